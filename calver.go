@@ -0,0 +1,241 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format used by the "calver" VersionScheme.
+const DefaultCalendarVersionFormat = "YYYY.0M.0D"
+
+// Calendar Version as described on https://calver.org.
+// Supported format tokens are:
+// YYYY, YY, 0Y, MM, 0M, WW, 0W, DD, 0D, MAJOR, MINOR and MICRO,
+// separated by ".", "-" or "_".
+type CalendarVersion struct {
+	format   string
+	segments []int
+}
+
+var (
+	_ Version = (*CalendarVersion)(nil)
+)
+
+func MustCalendarVersion(format, v string) *CalendarVersion {
+	cv, err := NewCalendarVersion(format, v)
+	if err != nil {
+		panic(err)
+	}
+	return cv
+}
+
+func NewCalendarVersion(format, v string) (*CalendarVersion, error) {
+	tokens, seps, err := parseCalendarVersionFormat(format)
+	if err != nil {
+		return nil, err
+	}
+
+	cv := &CalendarVersion{format: format}
+	rest := v
+	for i, token := range tokens {
+		var digits string
+		if i < len(seps) {
+			idx := strings.IndexByte(rest, seps[i])
+			if idx == -1 {
+				return nil, fmt.Errorf("invalid calendar version %q: expected %q", v, format)
+			}
+			digits, rest = rest[:idx], rest[idx+1:]
+		} else {
+			digits, rest = rest, ""
+		}
+
+		n, err := parseCalendarVersionSegment(token, digits)
+		if err != nil {
+			return nil, fmt.Errorf("invalid calendar version %q: %w", v, err)
+		}
+		cv.segments = append(cv.segments, n)
+	}
+
+	if err := cv.validateDate(tokens); err != nil {
+		return nil, fmt.Errorf("invalid calendar version %q: %w", v, err)
+	}
+	return cv, nil
+}
+
+// CalendarVersionParser returns a VersionParser for the given format,
+// e.g. to be used with ParseConstraint.
+func CalendarVersionParser(format string) VersionParser {
+	return func(v string) (Version, error) {
+		return NewCalendarVersion(format, v)
+	}
+}
+
+// CalendarVersionScheme returns a VersionScheme for the given format.
+// The scheme for DefaultCalendarVersionFormat is called "calver",
+// all others "calver:<format>".
+func CalendarVersionScheme(format string) VersionScheme {
+	return VersionScheme{
+		Name:  calendarVersionSchemeName(format),
+		Parse: CalendarVersionParser(format),
+	}
+}
+
+func (cv *CalendarVersion) Format() string {
+	return cv.format
+}
+
+func (cv *CalendarVersion) Equal(v Version) bool {
+	otherCV, ok := v.(*CalendarVersion)
+	if !ok || otherCV == nil || otherCV.format != cv.format {
+		return false
+	}
+	return cv.compare(otherCV) == 0
+}
+
+func (cv *CalendarVersion) Less(v Version) bool {
+	otherCV, ok := v.(*CalendarVersion)
+	if !ok || otherCV == nil || otherCV.format != cv.format {
+		return false
+	}
+	return cv.compare(otherCV) < 0
+}
+
+func (cv *CalendarVersion) String() string {
+	tokens, seps, _ := parseCalendarVersionFormat(cv.format)
+	var b strings.Builder
+	for i, token := range tokens {
+		n := cv.segments[i]
+		switch token {
+		case "0Y", "0M", "0W", "0D":
+			fmt.Fprintf(&b, "%02d", n)
+		case "YYYY":
+			fmt.Fprintf(&b, "%04d", n)
+		default:
+			b.WriteString(strconv.Itoa(n))
+		}
+		if i < len(seps) {
+			b.WriteByte(seps[i])
+		}
+	}
+	return b.String()
+}
+
+func (cv *CalendarVersion) Scheme() string {
+	return calendarVersionSchemeName(cv.format)
+}
+
+func (cv *CalendarVersion) compare(other *CalendarVersion) int {
+	for i := range cv.segments {
+		switch {
+		case cv.segments[i] < other.segments[i]:
+			return -1
+		case cv.segments[i] > other.segments[i]:
+			return 1
+		}
+	}
+	return 0
+}
+
+// Checks that the day exists, if the format contains year, month and day.
+func (cv *CalendarVersion) validateDate(tokens []string) error {
+	year, month, day := -1, -1, -1
+	for i, token := range tokens {
+		switch token {
+		case "YYYY":
+			year = cv.segments[i]
+		case "YY", "0Y":
+			year = 2000 + cv.segments[i]
+		case "MM", "0M":
+			month = cv.segments[i]
+		case "DD", "0D":
+			day = cv.segments[i]
+		}
+	}
+	if year == -1 || month == -1 || day == -1 {
+		return nil
+	}
+
+	t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if t.Day() != day {
+		return fmt.Errorf("day %d does not exist in %d-%02d", day, year, month)
+	}
+	return nil
+}
+
+func calendarVersionSchemeName(format string) string {
+	if format == DefaultCalendarVersionFormat {
+		return "calver"
+	}
+	return "calver:" + format
+}
+
+// Splits a format string into tokens and the separators between them.
+func parseCalendarVersionFormat(format string) (tokens []string, seps []byte, err error) {
+	start := 0
+	for i := 0; i <= len(format); i++ {
+		if i < len(format) && !strings.ContainsRune(".-_", rune(format[i])) {
+			continue
+		}
+
+		token := format[start:i]
+		switch token {
+		case "YYYY", "YY", "0Y", "MM", "0M", "WW", "0W", "DD", "0D",
+			"MAJOR", "MINOR", "MICRO":
+		default:
+			return nil, nil, fmt.Errorf("invalid calendar version format %q: unknown token %q", format, token)
+		}
+		tokens = append(tokens, token)
+		if i < len(format) {
+			seps = append(seps, format[i])
+		}
+		start = i + 1
+	}
+	return tokens, seps, nil
+}
+
+func parseCalendarVersionSegment(token, digits string) (int, error) {
+	if len(digits) == 0 {
+		return 0, fmt.Errorf("%s is empty", token)
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return 0, fmt.Errorf("%s %q is not a number", token, digits)
+		}
+	}
+	n, err := strconv.Atoi(digits)
+	if err != nil {
+		return 0, err
+	}
+
+	padded := token[0] == '0'
+	switch {
+	case token == "YYYY" && len(digits) != 4:
+		return 0, fmt.Errorf("%s %q must have 4 digits", token, digits)
+	case padded && (len(digits) < 2 || token != "0Y" && len(digits) != 2):
+		return 0, fmt.Errorf("%s %q must be padded to 2 digits", token, digits)
+	case !padded && token != "YYYY" && len(digits) > 1 && digits[0] == '0':
+		return 0, fmt.Errorf("%s %q must not have leading zeros", token, digits)
+	}
+
+	var lo, hi int
+	switch token {
+	case "YYYY":
+		lo, hi = 1, 9999
+	case "YY", "0Y":
+		lo, hi = 0, 999
+	case "MM", "0M":
+		lo, hi = 1, 12
+	case "WW", "0W":
+		lo, hi = 1, 53
+	case "DD", "0D":
+		lo, hi = 1, 31
+	default:
+		return n, nil
+	}
+	if n < lo || n > hi {
+		return 0, fmt.Errorf("%s %d out of range [%d, %d]", token, n, lo, hi)
+	}
+	return n, nil
+}
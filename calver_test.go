@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalendarVersion(t *testing.T) {
+	cv, err := NewCalendarVersion("YYYY.0M.0D", "2023.01.31")
+	require.NoError(t, err)
+
+	assert.Equal(t, "2023.01.31", cv.String())
+	assert.Equal(t, "calver", cv.Scheme())
+
+	assert.False(t, cv.Equal(nil))
+	assert.True(t, cv.Equal(cv))
+	assert.False(t, cv.Equal(MustCalendarVersion("YYYY.0M.0D", "2023.02.01")))
+	assert.False(t, cv.Equal(MustCalendarVersion("YYYY.MM.DD", "2023.1.31")))
+
+	assert.False(t, cv.Less(nil))
+	assert.False(t, cv.Less(MustCalendarVersion("YYYY.0M.0D", "2022.12.31")))
+	assert.True(t, cv.Less(MustCalendarVersion("YYYY.0M.0D", "2023.02.01")))
+
+	assert.Panics(t, func() {
+		MustCalendarVersion("YYYY.0M.0D", "xxx")
+	})
+}
+
+func TestCalendarVersion_formats(t *testing.T) {
+	tests := []struct {
+		format, version, expected string
+	}{
+		{format: "YYYY.MM.DD", version: "2023.1.5", expected: "2023.1.5"},
+		{format: "YY.MM.MICRO", version: "23.11.104", expected: "23.11.104"},
+		{format: "YYYY.0M", version: "2023.04", expected: "2023.04"},
+		{format: "0Y.0W-MAJOR", version: "106.52-3", expected: "106.52-3"},
+	}
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			cv, err := NewCalendarVersion(test.format, test.version)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, cv.String())
+		})
+	}
+}
+
+func TestCalendarVersion_invalid(t *testing.T) {
+	tests := []struct {
+		format, version, err string
+	}{
+		{
+			format: "YYYY.0M", version: "2023.4",
+			err: `invalid calendar version "2023.4": 0M "4" must be padded to 2 digits`,
+		},
+		{
+			format: "YYYY.MM", version: "2023.04",
+			err: `invalid calendar version "2023.04": MM "04" must not have leading zeros`,
+		},
+		{
+			format: "YYYY.MM", version: "2023.13",
+			err: `invalid calendar version "2023.13": MM 13 out of range [1, 12]`,
+		},
+		{
+			format: "YYYY.0M.0D", version: "2023.02.29",
+			err: `invalid calendar version "2023.02.29": day 29 does not exist in 2023-02`,
+		},
+		{
+			format: "YYYY.0M.0D", version: "2023.02",
+			err: `invalid calendar version "2023.02": expected "YYYY.0M.0D"`,
+		},
+		{
+			format: "YYYY.MONTH", version: "2023.02",
+			err: `invalid calendar version format "YYYY.MONTH": unknown token "MONTH"`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.version, func(t *testing.T) {
+			_, err := NewCalendarVersion(test.format, test.version)
+			require.EqualError(t, err, test.err)
+		})
+	}
+}
+
+func TestCalendarVersion_constraint(t *testing.T) {
+	c, err := ParseConstraint("!=2023.04", CalendarVersionParser("YYYY.0M"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "!=2023.04", c.String())
+	assert.True(t, c.Matches(MustCalendarVersion("YYYY.0M", "2023.05")))
+	assert.False(t, c.Matches(MustCalendarVersion("YYYY.0M", "2023.04")))
+
+	v, err := DefaultVersionSchemes.ParseVersion("calver", "2023.04.01")
+	require.NoError(t, err)
+	assert.Equal(t, "2023.04.01", v.String())
+}
+
+func TestCalendarVersionScheme_registry(t *testing.T) {
+	tests := []struct {
+		format     string
+		versions   [2]string
+		constraint string
+	}{
+		{format: "YY.MM.MICRO", versions: [2]string{"23.4.1", "23.5.0"}, constraint: ">=23.4.0"},
+		{format: "YYYY.0M", versions: [2]string{"2023.04", "2023.05"}, constraint: ">=2023.04"},
+	}
+	ctx := context.Background()
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			scheme := "calver:" + test.format
+			project, err := DefaultVersionSchemes.UnmarshalProject([]byte(fmt.Sprintf(`{
+				"name": "A",
+				"scheme": %q,
+				"versions": [
+					{"version": %q},
+					{"version": %q, "dependencies": [{"name": "B", "constraints": [%q]}]}
+				]
+			}`, scheme, test.versions[0], test.versions[1], test.constraint)))
+			require.NoError(t, err)
+			assert.Equal(t, scheme, project.Versions[0].Version.Scheme())
+			require.NoError(t, NewInMemoryDB().Add(ctx, project))
+		})
+	}
+
+	_, err := DefaultVersionSchemes.Get("calver:YYYY.FOO")
+	require.ErrorIs(t, err, ErrUnknownVersionScheme)
+	_, err = NewVersionSchemeRegistry(SemanticVersionScheme).Get("calver:YYYY.0M")
+	require.ErrorIs(t, err, ErrUnknownVersionScheme)
+}
+
+func FuzzNewCalendarVersion(f *testing.F) {
+	fuzzVersionRoundTrip(f, CalendarVersionParser(DefaultCalendarVersionFormat),
+		"2023.01.31", "2023.02.29", "2024.02.29", "23.1.1")
+}
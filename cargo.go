@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Cargo version requirement, e.g. "1.2, <1.5".
+// https://doc.rust-lang.org/cargo/reference/specifying-dependencies.html
+//
+// Comma separated comparators are ANDed, a bare version is a caret requirement:
+//
+//	1.2.3    >=1.2.3, <2.0.0
+//	^0.2.3   >=0.2.3, <0.3.0
+//	^0.0.3   >=0.0.3, <0.0.4
+//	~1.2     >=1.2.0, <1.3.0
+//	1.*      >=1.0.0, <2.0.0
+//	=1.2     >=1.2.0, <1.3.0
+//	>1.2     >=1.3.0
+//
+// Prereleases are handled like in npm ranges.
+type cargoRequirement struct {
+	raw string
+	set npmComparatorSet
+}
+
+var (
+	_ VersionConstraint = cargoRequirement{}
+	_ ExactConstraint   = cargoRequirement{}
+	_ DialectConstraint = cargoRequirement{}
+)
+
+// Name of the Cargo requirement dialect of the semver scheme.
+const CargoDialect = "cargo"
+
+// Operators in the order they are matched.
+var cargoOperators = []string{">=", "<=", "=", ">", "<", "~", "^"}
+
+// ParseCargoRequirement parses a Cargo version requirement into a constraint tree over SemanticVersion.
+func ParseCargoRequirement(req string) (VersionConstraint, error) {
+	r := cargoRequirement{raw: strings.TrimSpace(req)}
+	for _, s := range strings.Split(req, ",") {
+		set, err := parseCargoComparator(strings.TrimSpace(s))
+		if err != nil {
+			return nil, fmt.Errorf("invalid cargo requirement %q: %w", req, err)
+		}
+		r.set = append(r.set, set...)
+	}
+	return r, nil
+}
+
+func parseCargoComparator(s string) ([]npmComparator, error) {
+	var op string
+	for _, o := range cargoOperators {
+		if strings.HasPrefix(s, o) {
+			op = o
+			break
+		}
+	}
+
+	v := strings.TrimSpace(s[len(op):])
+	if len(v) == 0 {
+		return nil, fmt.Errorf("empty comparator %q", s)
+	}
+	if v[0] == 'v' {
+		return nil, fmt.Errorf("invalid version %q", v)
+	}
+	p, err := parseNPMPartial(v)
+	if err != nil {
+		return nil, err
+	}
+	// only the version core may contain wildcards, prerelease and build metadata may contain an "x".
+	core := strings.FieldsFunc(v, func(r rune) bool { return r == '-' || r == '+' })[0]
+	wildcard := strings.ContainsAny(core, "*xX")
+	if len(op) != 0 && wildcard {
+		return nil, fmt.Errorf("wildcard not allowed with %s", op)
+	}
+
+	switch op {
+	case "":
+		if wildcard {
+			return cargoWildcard(p)
+		}
+		return cargoCaret(p)
+	case "^":
+		return cargoCaret(p)
+	case "~":
+		return cargoTilde(p)
+	case "=":
+		if p.anyX() {
+			return cargoWildcard(p)
+		}
+		return npmSingle(npmCmp("=", p.major, p.minor, p.patch, p.prerelease))
+	}
+
+	if !p.anyX() {
+		return npmSingle(npmCmp(op, p.major, p.minor, p.patch, p.prerelease))
+	}
+	switch op {
+	case ">":
+		// >1 => >=2.0.0, >1.2 => >=1.3.0
+		return npmSingle(npmCmpNext(">=", p.major, p.minor, -1, ""))
+	case "<=":
+		// <=1 => <2.0.0, <=1.2 => <1.3.0
+		return npmSingle(npmCmpNext("<", p.major, p.minor, -1, ""))
+	default:
+		return npmSingle(npmCmp(op, p.major, zeroX(p.minor), 0, ""))
+	}
+}
+
+// * => >=0.0.0, 1.* => >=1.0.0, <2.0.0, 1.2.* => >=1.2.0, <1.3.0
+func cargoWildcard(p npmPartial) ([]npmComparator, error) {
+	if p.major == -1 {
+		return []npmComparator{npmAny()}, nil
+	}
+	return npmBounds(
+		func() (npmComparator, error) { return npmCmp(">=", p.major, zeroX(p.minor), 0, "") },
+		func() (npmComparator, error) { return npmCmpNext("<", p.major, p.minor, -1, "") },
+	)
+}
+
+// ~1.2.3 => >=1.2.3, <1.3.0, ~1 => >=1.0.0, <2.0.0
+func cargoTilde(p npmPartial) ([]npmComparator, error) {
+	if p.anyX() {
+		return cargoWildcard(p)
+	}
+	return npmBounds(
+		func() (npmComparator, error) { return npmCmp(">=", p.major, p.minor, p.patch, p.prerelease) },
+		func() (npmComparator, error) { return npmCmpNext("<", p.major, p.minor, -1, "") },
+	)
+}
+
+// The left-most non-zero component must not change.
+// ^0.0 => >=0.0.0, <0.1.0, ^0 => >=0.0.0, <1.0.0
+func cargoCaret(p npmPartial) ([]npmComparator, error) {
+	if p.major == -1 {
+		return []npmComparator{npmAny()}, nil
+	}
+	lower := func() (npmComparator, error) {
+		return npmCmp(">=", p.major, zeroX(p.minor), zeroX(p.patch), p.prerelease)
+	}
+	switch {
+	case p.major > 0 || p.minor == -1:
+		return npmBounds(lower, func() (npmComparator, error) { return npmCmpNext("<", p.major, -1, -1, "") })
+	case p.minor > 0 || p.patch == -1:
+		return npmBounds(lower, func() (npmComparator, error) { return npmCmpNext("<", 0, p.minor, -1, "") })
+	default:
+		return npmBounds(lower, func() (npmComparator, error) { return npmCmpNext("<", 0, 0, p.patch, "") })
+	}
+}
+
+func (r cargoRequirement) Matches(v Version) bool {
+	return r.set.Matches(v)
+}
+
+func (r cargoRequirement) String() string {
+	return r.raw
+}
+
+func (r cargoRequirement) Versions() []Version {
+	return r.set.Versions()
+}
+
+func (r cargoRequirement) Dialect() string {
+	return CargoDialect
+}
+
+func (r cargoRequirement) ExactVersion() (Version, bool) {
+	return r.set.ExactVersion()
+}
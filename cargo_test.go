@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Vectors from the tests of the semver crate used by Cargo.
+func TestParseCargoRequirement(t *testing.T) {
+	tests := []struct {
+		req      string
+		matches  []string
+		excludes []string
+	}{
+		{
+			req:      "1.0.0",
+			matches:  []string{"1.0.0", "1.1.0", "1.0.1"},
+			excludes: []string{"0.9.9", "0.10.0", "0.1.0", "1.0.0-pre", "1.0.1-pre"},
+		},
+		{
+			req:      "^1",
+			matches:  []string{"1.1.2", "1.1.0", "1.2.1", "1.0.1"},
+			excludes: []string{"0.9.1", "2.9.0", "0.1.4", "1.0.0-beta1", "0.1.0-alpha", "1.0.1-pre"},
+		},
+		{
+			req:      "^1.1",
+			matches:  []string{"1.1.2", "1.1.0", "1.2.1"},
+			excludes: []string{"0.9.1", "2.9.0", "1.0.1", "0.1.4"},
+		},
+		{
+			req:      "^1.1.2",
+			matches:  []string{"1.1.2", "1.1.4", "1.2.1"},
+			excludes: []string{"0.9.1", "2.9.0", "1.1.1", "0.0.1", "1.1.2-alpha1", "1.1.3-alpha1", "2.9.0-alpha1"},
+		},
+		{
+			req:      "^0.1.2",
+			matches:  []string{"0.1.2", "0.1.4"},
+			excludes: []string{"0.9.1", "0.0.1", "0.2.0", "0.1.2-beta", "0.1.3-alpha", "0.2.0-pre"},
+		},
+		{
+			req:      "^0.5.1-alpha3",
+			matches:  []string{"0.5.1-alpha3", "0.5.1-alpha4", "0.5.1-beta", "0.5.1", "0.5.5"},
+			excludes: []string{"0.5.1-alpha1", "0.5.2-alpha3", "0.5.5-pre", "0.5.0-pre", "0.6.0"},
+		},
+		{
+			req:      "^0.0.2",
+			matches:  []string{"0.0.2"},
+			excludes: []string{"0.9.1", "0.0.1", "0.1.4"},
+		},
+		{
+			req:      "^0.0",
+			matches:  []string{"0.0.2", "0.0.0"},
+			excludes: []string{"0.9.1", "0.1.4"},
+		},
+		{
+			req:      "^0",
+			matches:  []string{"0.9.1", "0.0.2", "0.0.0"},
+			excludes: []string{"2.9.0", "1.1.1"},
+		},
+		{
+			req:      "^1.4.2-beta.5",
+			matches:  []string{"1.4.2", "1.4.3", "1.4.2-beta.5", "1.4.2-beta.6", "1.4.2-c"},
+			excludes: []string{"0.9.9", "2.0.0", "1.4.2-alpha", "1.4.2-beta.4", "1.4.3-beta.5"},
+		},
+		{
+			req:      "~1",
+			matches:  []string{"1.0.0", "1.0.1", "1.1.1"},
+			excludes: []string{"0.9.1", "2.9.0", "0.0.9"},
+		},
+		{
+			req:      "~1.2",
+			matches:  []string{"1.2.0", "1.2.1"},
+			excludes: []string{"1.1.1", "1.3.0", "0.0.9"},
+		},
+		{
+			req:      "~1.2.2",
+			matches:  []string{"1.2.2", "1.2.4"},
+			excludes: []string{"1.2.1", "1.9.0", "1.0.9", "2.0.1", "0.1.3"},
+		},
+		{
+			req:      "~1.2.3-beta.2",
+			matches:  []string{"1.2.3", "1.2.4", "1.2.3-beta.2", "1.2.3-beta.4"},
+			excludes: []string{"1.3.3", "1.1.4", "1.2.3-beta.1", "1.2.4-beta.2"},
+		},
+		{
+			req:      "*",
+			matches:  []string{"0.1.0", "1.0.0"},
+			excludes: []string{"1.0.0-pre"},
+		},
+		{
+			req:      "1.*",
+			matches:  []string{"1.2.0", "1.2.1", "1.1.1", "1.3.0"},
+			excludes: []string{"0.0.9", "2.0.0"},
+		},
+		{
+			req:      "1.2.*",
+			matches:  []string{"1.2.0", "1.2.2", "1.2.4"},
+			excludes: []string{"1.9.0", "1.0.9", "2.0.1", "0.1.3"},
+		},
+		{
+			req:      "=0.1.0",
+			matches:  []string{"0.1.0"},
+			excludes: []string{"0.1.1", "0.0.1"},
+		},
+		{
+			req:      "=1",
+			matches:  []string{"1.0.0", "1.9.9"},
+			excludes: []string{"2.0.0", "0.9.9"},
+		},
+		{
+			req:      ">= 1.0.0",
+			matches:  []string{"1.0.0", "2.0.0"},
+			excludes: []string{"0.1.0", "0.0.1", "1.0.0-pre", "2.0.0-pre"},
+		},
+		{
+			req:      ">= 2.1.0-alpha2",
+			matches:  []string{"2.1.0-alpha2", "2.1.0-alpha3", "2.1.0", "3.0.0"},
+			excludes: []string{"2.0.0", "2.1.0-alpha1", "2.0.0-alpha2", "3.0.0-alpha2"},
+		},
+		{
+			req:      "> 0.0.9, <= 2.5.3",
+			matches:  []string{"0.0.10", "1.0.0", "2.5.3"},
+			excludes: []string{"0.0.8", "2.5.4"},
+		},
+		{
+			req:      ">=1.0.0-x.1",
+			matches:  []string{"1.0.0-x.1", "1.0.0"},
+			excludes: []string{"1.0.0-alpha"},
+		},
+		{
+			req:      "<1",
+			matches:  []string{"0.9.9"},
+			excludes: []string{"1.0.0"},
+		},
+		{
+			req:      "<= 1.2",
+			matches:  []string{"1.2.5"},
+			excludes: []string{"1.3.0"},
+		},
+		{
+			req:      ">1.2",
+			matches:  []string{"1.3.0"},
+			excludes: []string{"1.2.9"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.req, func(t *testing.T) {
+			c, err := ParseCargoRequirement(test.req)
+			require.NoError(t, err)
+			assert.Equal(t, test.req, c.String())
+
+			for _, v := range test.matches {
+				assert.True(t, c.Matches(MustSemanticVersion(v)), "should match %s", v)
+			}
+			for _, v := range test.excludes {
+				assert.False(t, c.Matches(MustSemanticVersion(v)), "should not match %s", v)
+			}
+		})
+	}
+}
+
+func TestParseCargoRequirement_invalid(t *testing.T) {
+	tests := map[string]string{
+		"":             `invalid cargo requirement "": empty comparator ""`,
+		">= >= 0.0.2":  `invalid cargo requirement ">= >= 0.0.2": invalid version ">= 0.0.2"`,
+		"v1.0.0":       `invalid cargo requirement "v1.0.0": invalid version "v1.0.0"`,
+		">=1.*":        `invalid cargo requirement ">=1.*": wildcard not allowed with >=`,
+		"1.0.0, ":      `invalid cargo requirement "1.0.0, ": empty comparator ""`,
+		"1.0.0-beta.*": `invalid cargo requirement "1.0.0-beta.*": invalid version "1.0.0-beta.*"`,
+
+		"^9223372036854775807": `invalid cargo requirement "^9223372036854775807": ` +
+			`major version 9223372036854775807 is too large`,
+		"9223372036854775807.x": `invalid cargo requirement "9223372036854775807.x": ` +
+			`major version 9223372036854775807 is too large`,
+		"~1.9223372036854775807": `invalid cargo requirement "~1.9223372036854775807": ` +
+			`minor version 9223372036854775807 is too large`,
+		"^0.0.9223372036854775807": `invalid cargo requirement "^0.0.9223372036854775807": ` +
+			`patch version 9223372036854775807 is too large`,
+	}
+	for req, expectedErr := range tests {
+		t.Run(req, func(t *testing.T) {
+			_, err := ParseCargoRequirement(req)
+			require.EqualError(t, err, expectedErr)
+		})
+	}
+}
+
+func TestResolver_cargo(t *testing.T) {
+	ctx := context.Background()
+	db := NewInMemoryDB()
+
+	mustReq := func(req string) VersionConstraint {
+		c, err := ParseCargoRequirement(req)
+		require.NoError(t, err)
+		return c
+	}
+
+	require.NoError(t, db.Add(ctx, Project{
+		Name: "tokio",
+		Versions: []ProjectVersion{
+			{Version: MustSemanticVersion("1.29.1"), Dependencies: []Dependency{
+				{Name: "mio", Constraints: []VersionConstraint{mustReq("0.8.8")}},
+			}},
+		},
+	}))
+	require.NoError(t, db.Add(ctx, Project{
+		Name: "mio",
+		Versions: []ProjectVersion{
+			{Version: MustSemanticVersion("0.9.0")},
+			{Version: MustSemanticVersion("0.8.9")},
+			{Version: MustSemanticVersion("0.8.8")},
+			{Version: MustSemanticVersion("0.8.7")},
+		},
+	}))
+
+	r := NewResolver(db)
+	result, err := r.Resolve(ctx, []Dependency{{Name: "tokio"}})
+	require.NoError(t, err)
+
+	sort.Sort(ResolverProjectVersionByName(result))
+	assert.Equal(t, []ResolverProjectVersion{
+		{Name: "mio", Version: "0.8.9"},
+		{Name: "tokio", Version: "1.29.1"},
+	}, result)
+}
+
+func FuzzParseCargoRequirement(f *testing.F) {
+	fuzzConstraintRoundTrip(f, ParseCargoRequirement,
+		"1.2.3", "^0.1", "~1", ">=1.2, <1.5", "=1.2.3-rc.1", "*",
+		"^9223372036854775807", "9223372036854775807.x", ">9223372036854775807",
+		"~1.9223372036854775807", "^0.0.9223372036854775807", "<=1.9223372036854775807")
+}
@@ -6,6 +6,36 @@ import (
 	"strings"
 )
 
+// Implemented by all nodes of a constraint tree.
+type VersionConstraint interface {
+	Matches(v Version) bool
+	String() string
+	// Versions returns all versions referenced by the constraint.
+	Versions() []Version
+}
+
+// Optionally implemented by constraints that only match a single version,
+// e.g. "=1.2.3", so yanked versions can still be pinned in every dialect.
+type ExactConstraint interface {
+	// ExactVersion returns the only version matched by the constraint.
+	ExactVersion() (Version, bool)
+}
+
+// Implemented by constraints parsed by a VersionScheme dialect,
+// so they are parsed by the same dialect when deserialized.
+type DialectConstraint interface {
+	// Dialect returns the name of the dialect, e.g. "npm".
+	Dialect() string
+}
+
+var (
+	_ VersionConstraint = Constraint{}
+	_ VersionConstraint = ConstraintAND{}
+	_ VersionConstraint = ConstraintOR{}
+
+	_ ExactConstraint = Constraint{}
+)
+
 type Constraint struct {
 	operator Operator
 	version  Version
@@ -17,46 +47,64 @@ func NewConstraint(op Operator, v Version) *Constraint {
 
 type VersionParser func(v string) (Version, error)
 
+// All known operators.
+var operators = []Operator{Equal, NotEqual, Greater, Less, GreaterOrEqual, LessOrEqual}
+
+// ParseConstraint parses an operator followed by a version.
+// The longest matching operator wins, so the order of operators does not matter.
 func ParseConstraint(constraint string, parseVersion VersionParser) (*Constraint, error) {
-	for _, op := range []Operator{Equal, NotEqual, Greater, Less, GreaterOrEqual, LessOrEqual} {
-		if strings.HasPrefix(constraint, string(op)) {
-			v, err := parseVersion(constraint[len(op):])
-			if err != nil {
-				return nil, err
-			}
-			return NewConstraint(op, v), nil
+	var op Operator
+	for _, candidate := range operators {
+		if strings.HasPrefix(constraint, string(candidate)) && len(candidate) > len(op) {
+			op = candidate
 		}
 	}
-	return nil, fmt.Errorf("unknown op")
+	if len(op) == 0 {
+		return nil, fmt.Errorf("unknown op")
+	}
+
+	v, err := parseVersion(constraint[len(op):])
+	if err != nil {
+		return nil, err
+	}
+	return NewConstraint(op, v), nil
 }
 
-func (c *Constraint) String() string {
+func (c Constraint) String() string {
 	return string(c.operator) + c.version.String()
 }
 
-func (c *Constraint) MarshalJSON() ([]byte, error) {
+func (c Constraint) MarshalJSON() ([]byte, error) {
 	return json.Marshal(c.String())
 }
 
-func (c *Constraint) Matches(v Version) bool {
-	switch c.operator {
-	case Equal:
-		return c.version.Equal(v)
-	case NotEqual:
-		return !c.version.Equal(v)
-	case Greater:
-		return !c.version.Less(v) && !c.version.Equal(v)
-	case Less:
-		return c.version.Less(v)
-	case GreaterOrEqual:
-		return !c.version.Less(v)
-	case LessOrEqual:
-		return c.version.Less(v) || c.version.Equal(v)
-	default:
-		return false
+// Matches reports whether "v <operator> constraint version" holds.
+// Versions of other schemes only match NotEqual.
+func (c Constraint) Matches(v Version) bool {
+	if v == nil || v.Scheme() != c.version.Scheme() {
+		return c.operator == NotEqual
 	}
+	return c.operator.compares(compareVersions(v, c.version))
+}
+
+func (c Constraint) Versions() []Version {
+	return []Version{c.version}
 }
 
+func (c Constraint) ExactVersion() (Version, bool) {
+	return c.version, c.operator == Equal
+}
+
+// Operator of a Constraint.
+//
+// A constraint is written as an operator directly followed by a version,
+// e.g. ">=1.2.3". It reads left to right with the candidate version in front:
+// a version v matches ">=1.2.3", if "v >= 1.2.3" holds.
+//
+//	constraint = operator version
+//	operator   = "=" | "!=" | ">" | "<" | ">=" | "<="
+//
+// Ordering is defined by Version.Less and Version.Equal of the version scheme.
 type Operator string
 
 const (
@@ -67,3 +115,23 @@ const (
 	GreaterOrEqual Operator = ">="
 	LessOrEqual    Operator = "<="
 )
+
+// Reports whether the result of comparing a version with the constraint version satisfies the operator.
+func (op Operator) compares(c int) bool {
+	switch op {
+	case Equal:
+		return c == 0
+	case NotEqual:
+		return c != 0
+	case Greater:
+		return c > 0
+	case Less:
+		return c < 0
+	case GreaterOrEqual:
+		return c >= 0
+	case LessOrEqual:
+		return c <= 0
+	default:
+		return false
+	}
+}
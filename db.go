@@ -3,35 +3,144 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sort"
 )
 
 type ProjectDB interface {
 	Add(ctx context.Context, project Project) error
 	Get(ctx context.Context, projectName string) (Project, error)
+	// Providers returns all projects with a version providing or replacing the capability.
+	Providers(ctx context.Context, capabilityName string) ([]Project, error)
 }
 
 var (
 	ErrNotFound = errors.New("not found")
+	// Returned when a project contains the same version twice,
+	// or versions only differing in build metadata under BuildMetadataReject.
+	ErrDuplicateVersion = errors.New("duplicate version")
 )
 
 type InMemoryDB struct {
 	// data indexed by project name
 	data map[string]Project
+	// validates the versions of added projects against their scheme
+	schemes *VersionSchemeRegistry
+	// versions collapsed into equivalents, in the order they were added
+	collapsed []CollapsedVersion
 }
 
-func NewInMemoryDB() *InMemoryDB {
-	return &InMemoryDB{
-		data: map[string]Project{},
+// Reports a version that was dropped under BuildMetadataIgnore,
+// because an equivalent version of the project was added before it.
+type CollapsedVersion struct {
+	ProjectName string
+	Version     Version
+	Into        Version
+}
+
+func (cv CollapsedVersion) String() string {
+	return fmt.Sprintf("project %q: %s collapsed into equivalent %s", cv.ProjectName, cv.Version, cv.Into)
+}
+
+type InMemoryDBOption func(db *InMemoryDB)
+
+// WithVersionSchemes validates projects declaring a scheme against the given registry,
+// e.g. to allow custom schemes. Defaults to DefaultVersionSchemes.
+func WithVersionSchemes(schemes *VersionSchemeRegistry) InMemoryDBOption {
+	return func(db *InMemoryDB) {
+		db.schemes = schemes
+	}
+}
+
+func NewInMemoryDB(opts ...InMemoryDBOption) *InMemoryDB {
+	db := &InMemoryDB{
+		data:    map[string]Project{},
+		schemes: DefaultVersionSchemes,
+	}
+	for _, opt := range opts {
+		opt(db)
 	}
+	return db
 }
 
 func (db *InMemoryDB) Add(ctx context.Context, project Project) error {
-	sort.Sort(ProjectVersionsDescending(project.Versions))
+	// only an explicitly declared scheme is enforced.
+	if len(project.Scheme) != 0 {
+		if err := db.schemes.ValidateProject(project); err != nil {
+			return err
+		}
+	}
+	versions, collapsed, err := uniqueProjectVersions(project)
+	if err != nil {
+		return err
+	}
+	project.Versions = versions
+
+	// replace reports of an earlier Add of the same project.
+	var kept []CollapsedVersion
+	for _, cv := range db.collapsed {
+		if cv.ProjectName != project.Name {
+			kept = append(kept, cv)
+		}
+	}
+	db.collapsed = append(kept, collapsed...)
+
+	sort.Sort(ProjectVersionsMigratedDescending{
+		Versions:   project.Versions,
+		Migrations: project.Migrations,
+	})
 	db.data[project.Name] = project
 	return nil
 }
 
+// Collapsed returns the versions that were dropped by Add, because they are equivalent to another version.
+func (db *InMemoryDB) Collapsed() []CollapsedVersion {
+	return db.collapsed
+}
+
+// Validates project versions for duplicates and equivalents.
+// Equivalent versions, that are not rejected, are collapsed into the first occurrence and reported.
+func uniqueProjectVersions(project Project) ([]ProjectVersion, []CollapsedVersion, error) {
+	var (
+		versions  []ProjectVersion
+		collapsed []CollapsedVersion
+	)
+	for _, pv := range project.Versions {
+		var into Version
+		for _, existing := range versions {
+			if pv.Version.String() == existing.Version.String() {
+				return nil, nil, fmt.Errorf("project %q: %w %s",
+					project.Name, ErrDuplicateVersion, pv.Version)
+			}
+			if !pv.Version.Equal(existing.Version) {
+				continue
+			}
+			if rejectsBuildMetadata(pv.Version) || rejectsBuildMetadata(existing.Version) {
+				return nil, nil, fmt.Errorf("project %q: %w %s is equivalent to %s",
+					project.Name, ErrDuplicateVersion, pv.Version, existing.Version)
+			}
+			if into == nil {
+				into = existing.Version
+			}
+		}
+		if into != nil {
+			collapsed = append(collapsed, CollapsedVersion{
+				ProjectName: project.Name,
+				Version:     pv.Version,
+				Into:        into,
+			})
+			continue
+		}
+		versions = append(versions, pv)
+	}
+	return versions, collapsed, nil
+}
+
+func rejectsBuildMetadata(v Version) bool {
+	sv, ok := v.(*SemanticVersion)
+	return ok && sv.metadataMode == BuildMetadataReject
+}
+
 func (db *InMemoryDB) Get(ctx context.Context, projectName string) (Project, error) {
 	project, ok := db.data[projectName]
 	if !ok {
@@ -39,3 +148,33 @@ func (db *InMemoryDB) Get(ctx context.Context, projectName string) (Project, err
 	}
 	return project, nil
 }
+
+// Providers returns all projects with a version providing or replacing the capability, sorted by name.
+func (db *InMemoryDB) Providers(ctx context.Context, capabilityName string) ([]Project, error) {
+	var providers []Project
+	for _, project := range db.data {
+		if projectProvides(project, capabilityName) {
+			providers = append(providers, project)
+		}
+	}
+	sort.Slice(providers, func(i, j int) bool {
+		return providers[i].Name < providers[j].Name
+	})
+	return providers, nil
+}
+
+func projectProvides(project Project, capabilityName string) bool {
+	for _, pv := range project.Versions {
+		for _, c := range pv.Provides {
+			if c.Name == capabilityName {
+				return true
+			}
+		}
+		for _, c := range pv.Replaces {
+			if c.Name == capabilityName {
+				return true
+			}
+		}
+	}
+	return false
+}
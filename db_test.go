@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryDB_Add_duplicates(t *testing.T) {
+	versions := func(mode BuildMetadataMode, vs ...string) []ProjectVersion {
+		var pvs []ProjectVersion
+		for _, v := range vs {
+			sv, err := NewSemanticVersionWithBuildMetadata(v, mode)
+			require.NoError(t, err)
+			pvs = append(pvs, ProjectVersion{Version: sv})
+		}
+		return pvs
+	}
+
+	tests := []struct {
+		name     string
+		versions []ProjectVersion
+		expected []string
+		// versions reported as collapsed into an equivalent
+		collapsed []string
+		err       string
+	}{
+		{
+			name:     "duplicate",
+			versions: versions(BuildMetadataIgnore, "1.0.0", "1.1.0", "1.0.0"),
+			err:      `project "A": duplicate version 1.0.0`,
+		},
+		{
+			name:      "ignore collapses equivalents",
+			versions:  versions(BuildMetadataIgnore, "1.0.0+a", "1.1.0", "1.0.0+b"),
+			expected:  []string{"1.1.0", "1.0.0+a"},
+			collapsed: []string{`project "A": 1.0.0+b collapsed into equivalent 1.0.0+a`},
+		},
+		{
+			name:     "reject",
+			versions: versions(BuildMetadataReject, "1.0.0+a", "1.0.0+b"),
+			err:      `project "A": duplicate version 1.0.0+b is equivalent to 1.0.0+a`,
+		},
+		{
+			name:     "tie-break keeps all",
+			versions: versions(BuildMetadataTieBreak, "1.0.0+a", "1.0.0", "1.0.0+b"),
+			expected: []string{"1.0.0+b", "1.0.0+a", "1.0.0"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			db := NewInMemoryDB()
+			err := db.Add(ctx, Project{Name: "A", Versions: test.versions})
+			if len(test.err) > 0 {
+				require.EqualError(t, err, test.err)
+				assert.ErrorIs(t, err, ErrDuplicateVersion)
+				return
+			}
+			require.NoError(t, err)
+
+			project, err := db.Get(ctx, "A")
+			require.NoError(t, err)
+			var actual []string
+			for _, pv := range project.Versions {
+				actual = append(actual, pv.Version.String())
+			}
+			assert.Equal(t, test.expected, actual)
+
+			var collapsed []string
+			for _, cv := range db.Collapsed() {
+				collapsed = append(collapsed, cv.String())
+			}
+			assert.Equal(t, test.collapsed, collapsed)
+		})
+	}
+}
+
+func TestInMemoryDB_Add_scheme(t *testing.T) {
+	ctx := context.Background()
+	project := Project{
+		Name:   "A",
+		Scheme: "sequence",
+		Versions: []ProjectVersion{
+			{Version: MustSemanticVersion("1.0.0")},
+		},
+	}
+
+	err := NewInMemoryDB().Add(ctx, project)
+	require.EqualError(t, err,
+		`project "A" uses version scheme "sequence", but version 1.0.0 is "semver"`)
+
+	project.Scheme = "custom"
+	err = NewInMemoryDB().Add(ctx, project)
+	require.ErrorIs(t, err, ErrUnknownVersionScheme)
+
+	schemes := NewVersionSchemeRegistry(VersionScheme{Name: "custom", Parse: ParseSemanticVersion})
+	err = NewInMemoryDB(WithVersionSchemes(schemes)).Add(ctx, project)
+	require.EqualError(t, err,
+		`project "A" uses version scheme "custom", but version 1.0.0 is "semver"`)
+
+	// projects without a declared scheme are not validated.
+	require.NoError(t, NewInMemoryDB().Add(ctx, Project{
+		Name:     "S",
+		Versions: []ProjectVersion{{Version: SequenceVersion(1)}},
+	}))
+}
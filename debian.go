@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Debian package version: [epoch:]upstream_version[-debian_revision].
+// https://www.debian.org/doc/debian-policy/ch-controlfields.html#version
+type DebianVersion struct {
+	epoch    int
+	upstream string
+	revision string
+}
+
+var (
+	_ Version = (*DebianVersion)(nil)
+)
+
+var DebianVersionScheme = VersionScheme{
+	Name:  "debian",
+	Parse: ParseDebianVersion,
+}
+
+func MustDebianVersion(v string) *DebianVersion {
+	dv, err := NewDebianVersion(v)
+	if err != nil {
+		panic(err)
+	}
+	return dv
+}
+
+func NewDebianVersion(v string) (*DebianVersion, error) {
+	dv := &DebianVersion{}
+	rest := strings.TrimSpace(v)
+
+	if i := strings.IndexByte(rest, ':'); i != -1 {
+		epoch, err := strconv.Atoi(rest[:i])
+		if err != nil || epoch < 0 {
+			return nil, fmt.Errorf("invalid debian version %q: epoch is not a number", v)
+		}
+		dv.epoch = epoch
+		rest = rest[i+1:]
+	}
+	if i := strings.LastIndexByte(rest, '-'); i != -1 {
+		dv.revision = rest[i+1:]
+		rest = rest[:i]
+		if len(dv.revision) == 0 {
+			return nil, fmt.Errorf("invalid debian version %q: empty revision", v)
+		}
+	}
+	dv.upstream = rest
+
+	if len(dv.upstream) == 0 {
+		return nil, fmt.Errorf("invalid debian version %q: empty upstream version", v)
+	}
+	if dv.upstream[0] < '0' || dv.upstream[0] > '9' {
+		return nil, fmt.Errorf("invalid debian version %q: upstream version must start with a digit", v)
+	}
+	for _, r := range dv.upstream {
+		if !isASCIIAlnum(r) && !strings.ContainsRune(".+~-:", r) {
+			return nil, fmt.Errorf("invalid debian version %q: invalid character %q in upstream version", v, r)
+		}
+	}
+	for _, r := range dv.revision {
+		if !isASCIIAlnum(r) && !strings.ContainsRune(".+~", r) {
+			return nil, fmt.Errorf("invalid debian version %q: invalid character %q in revision", v, r)
+		}
+	}
+	return dv, nil
+}
+
+func ParseDebianVersion(v string) (Version, error) {
+	return NewDebianVersion(v)
+}
+
+func (dv *DebianVersion) Equal(v Version) bool {
+	otherDV, ok := v.(*DebianVersion)
+	if !ok || otherDV == nil {
+		return false
+	}
+	return dv.compare(otherDV) == 0
+}
+
+func (dv *DebianVersion) Less(v Version) bool {
+	otherDV, ok := v.(*DebianVersion)
+	if !ok || otherDV == nil {
+		return false
+	}
+	return dv.compare(otherDV) < 0
+}
+
+func (dv *DebianVersion) String() string {
+	s := dv.upstream
+	if dv.epoch != 0 {
+		s = strconv.Itoa(dv.epoch) + ":" + s
+	}
+	if len(dv.revision) != 0 {
+		s += "-" + dv.revision
+	}
+	return s
+}
+
+func (dv *DebianVersion) Scheme() string {
+	return DebianVersionScheme.Name
+}
+
+func (dv *DebianVersion) compare(other *DebianVersion) int {
+	if c := compareInt(dv.epoch, other.epoch); c != 0 {
+		return c
+	}
+	if c := debianVerRevCmp(dv.upstream, other.upstream); c != 0 {
+		return c
+	}
+	return debianVerRevCmp(dv.revision, other.revision)
+}
+
+// Port of verrevcmp from dpkg.
+// Compares alternating non-digit and digit parts,
+// non-digit parts are compared using debianCharOrder,
+// digit parts numerically.
+func debianVerRevCmp(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		for (i < len(a) && !isASCIIDigit(a[i])) || (j < len(b) && !isASCIIDigit(b[j])) {
+			ac, bc := debianCharOrder(a, i), debianCharOrder(b, j)
+			if ac != bc {
+				return compareInt(ac, bc)
+			}
+			i++
+			j++
+		}
+
+		for i < len(a) && a[i] == '0' {
+			i++
+		}
+		for j < len(b) && b[j] == '0' {
+			j++
+		}
+
+		firstDiff := 0
+		for i < len(a) && isASCIIDigit(a[i]) && j < len(b) && isASCIIDigit(b[j]) {
+			if firstDiff == 0 {
+				firstDiff = compareInt(int(a[i]), int(b[j]))
+			}
+			i++
+			j++
+		}
+		if i < len(a) && isASCIIDigit(a[i]) {
+			return 1
+		}
+		if j < len(b) && isASCIIDigit(b[j]) {
+			return -1
+		}
+		if firstDiff != 0 {
+			return firstDiff
+		}
+	}
+	return 0
+}
+
+// "~" sorts before everything, even the end of the string,
+// followed by letters and then all other characters.
+func debianCharOrder(s string, i int) int {
+	if i >= len(s) {
+		return 0
+	}
+	c := s[i]
+	switch {
+	case isASCIIDigit(c):
+		return 0
+	case isASCIIAlpha(c):
+		return int(c)
+	case c == '~':
+		return -1
+	default:
+		return int(c) + 256
+	}
+}
+
+func isASCIIDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isASCIIAlpha(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isASCIIAlnum(r rune) bool {
+	return r < 0x80 && (isASCIIDigit(byte(r)) || isASCIIAlpha(byte(r)))
+}
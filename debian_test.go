@@ -0,0 +1,112 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebianVersion(t *testing.T) {
+	dv, err := NewDebianVersion("1:2.30-1ubuntu1")
+	require.NoError(t, err)
+
+	assert.Equal(t, "1:2.30-1ubuntu1", dv.String())
+	assert.Equal(t, "debian", dv.Scheme())
+
+	assert.False(t, dv.Equal(nil))
+	assert.True(t, dv.Equal(dv))
+	assert.False(t, dv.Equal(MustDebianVersion("2.30-1ubuntu1")))
+
+	assert.False(t, dv.Less(nil))
+	assert.False(t, dv.Less(MustDebianVersion("9.0")))
+	assert.True(t, dv.Less(MustDebianVersion("1:2.30-1ubuntu2")))
+
+	assert.Panics(t, func() {
+		MustDebianVersion("xxx")
+	})
+}
+
+func TestNewDebianVersion_invalid(t *testing.T) {
+	tests := map[string]string{
+		"":        `invalid debian version "": empty upstream version`,
+		"a:1.0":   `invalid debian version "a:1.0": epoch is not a number`,
+		"1.0-":    `invalid debian version "1.0-": empty revision`,
+		"abc":     `invalid debian version "abc": upstream version must start with a digit`,
+		"1.0_1":   `invalid debian version "1.0_1": invalid character '_' in upstream version`,
+		"1.0-1:2": `invalid debian version "1.0-1:2": epoch is not a number`,
+		"1.0-a_b": `invalid debian version "1.0-a_b": invalid character '_' in revision`,
+	}
+	for v, expectedErr := range tests {
+		t.Run(v, func(t *testing.T) {
+			_, err := NewDebianVersion(v)
+			require.EqualError(t, err, expectedErr)
+		})
+	}
+}
+
+func TestDebianVersion_compare(t *testing.T) {
+	// Comparison vectors from dpkg's t-version test and Debian Policy 5.6.12.
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"0", "0", 0},
+		{"0", "00", 0},
+		{"1.2.3", "1.2.3", 0},
+		{"4.4.3-2", "4.4.3-2", 0},
+		{"1:2ab:5", "1:2ab:5", 0},
+		{"7:1-a:b-5", "7:1-a:b-5", 0},
+		{"57:1.2.3abYZ+~-4-5", "57:1.2.3abYZ+~-4-5", 0},
+		{"1.2.3", "0:1.2.3", 0},
+		{"1.2.3", "1.2.3-0", 0},
+		{"009", "9", 0},
+		{"009ab5", "9ab5", 0},
+		{"1.002", "1.2", 0},
+		{"1.2.3", "1.2.3-1", -1},
+		{"1.2.3", "1.2.4", -1},
+		{"1.2.4", "1.2.3", 1},
+		{"1.2.24", "1.2.3", 1},
+		{"0.10.0", "0.8.7", 1},
+		{"3.2", "2.3", 1},
+		{"1.3.2a", "1.3.2", 1},
+		{"0.5.0~git", "0.5.0~git2", -1},
+		{"2a", "21", -1},
+		{"1.2a+~bCd3", "1.2a++", -1},
+		{"1.2a+~bCd3", "1.2a+~", 1},
+		{"5:2", "304-2", 1},
+		{"5:2", "304:2", -1},
+		{"25:2", "3:2", 1},
+		{"1:2:123", "1:12:3", -1},
+		{"1.2-5", "1.2-3-5", -1},
+		{"5.10.0", "5.005", 1},
+		{"3a9.8", "3.10.2", -1},
+		{"3.10.2", "3a9.8", 1},
+		{"1.4+OOo3.0.0~", "1.4+OOo3.0.0-4", -1},
+		{"2.4.7-1", "2.4.7-z", -1},
+		{"1.002-1+b2", "1.00", 1},
+		// ~~ < ~~a < ~ < (empty) < a
+		{"1.0~~", "1.0~~a", -1},
+		{"1.0~~a", "1.0~", -1},
+		{"1.0~", "1.0", -1},
+		{"1.0", "1.0a", -1},
+		{"1.0~rc1", "1.0", -1},
+		{"1.0+b1", "1.0", 1},
+		{"1.0.1", "1.0a", 1},
+		{"1.0+", "1.0a", 1},
+	}
+	for _, test := range tests {
+		t.Run(test.a+" "+test.b, func(t *testing.T) {
+			a, b := MustDebianVersion(test.a), MustDebianVersion(test.b)
+			assert.Equal(t, test.expected, a.compare(b))
+			assert.Equal(t, -test.expected, b.compare(a))
+			assert.Equal(t, test.expected == 0, a.Equal(b))
+			assert.Equal(t, test.expected < 0, a.Less(b))
+		})
+	}
+}
+
+func FuzzNewDebianVersion(f *testing.F) {
+	fuzzVersionRoundTrip(f, ParseDebianVersion,
+		"1:2.30-1ubuntu1", "1.0~rc1", "1.0+b1-2", "0:1.0", "1.0-1-2")
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Named set of additional dependencies of a ProjectVersion,
+// like Cargo features or Python extras.
+type Feature struct {
+	Name         string
+	Dependencies []Dependency
+}
+
+// ParseDependency parses a project name with optional features, e.g. "A[tls,metrics]".
+func ParseDependency(s string) (Dependency, error) {
+	s = strings.TrimSpace(s)
+	open := strings.IndexByte(s, '[')
+	if open == -1 {
+		if len(s) == 0 || strings.ContainsAny(s, "],") {
+			return Dependency{}, fmt.Errorf("invalid dependency %q", s)
+		}
+		return Dependency{Name: s}, nil
+	}
+
+	name := strings.TrimSpace(s[:open])
+	if len(name) == 0 {
+		return Dependency{}, fmt.Errorf("invalid dependency %q: empty name", s)
+	}
+	if !strings.HasSuffix(s, "]") {
+		return Dependency{}, fmt.Errorf("invalid dependency %q: missing ']'", s)
+	}
+
+	dep := Dependency{Name: name}
+	list := s[open+1 : len(s)-1]
+	if strings.TrimSpace(list) == "" {
+		return dep, nil
+	}
+	for _, feature := range strings.Split(list, ",") {
+		feature = strings.TrimSpace(feature)
+		if len(feature) == 0 || strings.ContainsAny(feature, "[]") {
+			return Dependency{}, fmt.Errorf("invalid dependency %q: invalid feature %q", s, feature)
+		}
+		dep.Features = append(dep.Features, feature)
+	}
+	return dep, nil
+}
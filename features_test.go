@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDependency(t *testing.T) {
+	tests := map[string]Dependency{
+		"A":                   {Name: "A"},
+		" A ":                 {Name: "A"},
+		"A[]":                 {Name: "A"},
+		"A[tls]":              {Name: "A", Features: []string{"tls"}},
+		"A[tls,metrics]":      {Name: "A", Features: []string{"tls", "metrics"}},
+		"A [ tls , metrics ]": {Name: "A", Features: []string{"tls", "metrics"}},
+	}
+	for s, expected := range tests {
+		t.Run(s, func(t *testing.T) {
+			dep, err := ParseDependency(s)
+			require.NoError(t, err)
+			assert.Equal(t, expected, dep)
+		})
+	}
+}
+
+func TestParseDependency_invalid(t *testing.T) {
+	tests := map[string]string{
+		"":            `invalid dependency ""`,
+		"A]":          `invalid dependency "A]"`,
+		"[tls]":       `invalid dependency "[tls]": empty name`,
+		"A[tls":       `invalid dependency "A[tls": missing ']'`,
+		"A[tls,]":     `invalid dependency "A[tls,]": invalid feature ""`,
+		"A[tls[x]]":   `invalid dependency "A[tls[x]]": invalid feature "tls[x]"`,
+		"A[tls],B[x]": `invalid dependency "A[tls],B[x]": invalid feature "tls]"`,
+	}
+	for s, expectedErr := range tests {
+		t.Run(s, func(t *testing.T) {
+			_, err := ParseDependency(s)
+			require.EqualError(t, err, expectedErr)
+		})
+	}
+}
+
+func TestResolver_features(t *testing.T) {
+	projectReqwest, err := DefaultVersionSchemes.UnmarshalProject([]byte(`{
+		"name": "reqwest",
+		"versions": [
+			{"version": "2.0.0", "features": [
+				{"name": "tls", "dependencies": [{"name": "rustls"}]},
+				{"name": "metrics", "dependencies": [{"name": "prometheus", "constraints": [">=1.0.0"]}]},
+				{"name": "full", "dependencies": [{"name": "reqwest", "features": ["tls", "metrics"]}]}
+			]},
+			{"version": "1.0.0", "features": [
+				{"name": "tls", "dependencies": [{"name": "rustls"}]}
+			]}
+		]
+	}`))
+	require.NoError(t, err)
+	var (
+		projectRustls = Project{
+			Name:     "rustls",
+			Versions: []ProjectVersion{{Version: MustSemanticVersion("1.0.0")}},
+		}
+		projectPrometheus = Project{
+			Name:     "prometheus",
+			Versions: []ProjectVersion{{Version: MustSemanticVersion("1.2.0")}},
+		}
+	)
+
+	ctx := context.Background()
+	db := NewInMemoryDB()
+	for _, p := range []Project{projectReqwest, projectRustls, projectPrometheus} {
+		require.NoError(t, db.Add(ctx, p))
+	}
+
+	mustParseDependency := func(s string, constraints ...VersionConstraint) Dependency {
+		dep, err := ParseDependency(s)
+		require.NoError(t, err)
+		dep.Constraints = constraints
+		return dep
+	}
+	below2 := *NewConstraint(Less, MustSemanticVersion("2.0.0"))
+
+	tests := []struct {
+		name     string
+		rootDeps []Dependency
+		expected []ResolverProjectVersion
+		// features of the selected reqwest version
+		features []string
+	}{
+		{
+			name:     "no features",
+			rootDeps: []Dependency{mustParseDependency("reqwest")},
+			expected: []ResolverProjectVersion{
+				{Name: "reqwest", Version: "2.0.0"},
+			},
+		},
+		{
+			name:     "features",
+			rootDeps: []Dependency{mustParseDependency("reqwest[tls,metrics]")},
+			expected: []ResolverProjectVersion{
+				{Name: "prometheus", Version: "1.2.0"},
+				{Name: "reqwest", Version: "2.0.0"},
+				{Name: "rustls", Version: "1.0.0"},
+			},
+			features: []string{"metrics", "tls"},
+		},
+		{
+			name:     "feature enabling features",
+			rootDeps: []Dependency{mustParseDependency("reqwest[full]")},
+			expected: []ResolverProjectVersion{
+				{Name: "prometheus", Version: "1.2.0"},
+				{Name: "reqwest", Version: "2.0.0"},
+				{Name: "rustls", Version: "1.0.0"},
+			},
+			features: []string{"full", "metrics", "tls"},
+		},
+		{
+			name:     "feature of older version",
+			rootDeps: []Dependency{mustParseDependency("reqwest[tls]", below2)},
+			expected: []ResolverProjectVersion{
+				{Name: "reqwest", Version: "1.0.0"},
+				{Name: "rustls", Version: "1.0.0"},
+			},
+			features: []string{"tls"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := NewResolver(db)
+			result, err := r.Resolve(ctx, test.rootDeps)
+			require.NoError(t, err)
+
+			sort.Sort(ResolverProjectVersionByName(result))
+			assert.Equal(t, test.expected, result)
+			for _, rpv := range result {
+				if rpv.Name == "reqwest" {
+					assert.Equal(t, test.features, r.FeaturesFor(ctx, rpv))
+				}
+			}
+		})
+	}
+
+	t.Run("side by side", func(t *testing.T) {
+		r := NewResolver(db, WithUniquenessKey(MajorVersionKey, "reqwest"))
+		result, err := r.Resolve(ctx, []Dependency{
+			mustParseDependency("reqwest[metrics]", *NewConstraint(GreaterOrEqual, MustSemanticVersion("2.0.0"))),
+			mustParseDependency("reqwest", below2),
+		})
+		require.NoError(t, err)
+
+		sort.Slice(result, func(i, j int) bool { return result[i].String() < result[j].String() })
+		assert.Equal(t, []ResolverProjectVersion{
+			{Name: "prometheus", Version: "1.2.0"},
+			{Name: "reqwest", Version: "1.0.0"},
+			{Name: "reqwest", Version: "2.0.0"},
+		}, result)
+		assert.Equal(t, []string{"metrics"}, r.FeaturesFor(ctx, ResolverProjectVersion{Name: "reqwest", Version: "2.0.0"}))
+		assert.Empty(t, r.FeaturesFor(ctx, ResolverProjectVersion{Name: "reqwest", Version: "1.0.0"}))
+	})
+
+	t.Run("missing feature", func(t *testing.T) {
+		r := NewResolver(db)
+		_, err := r.Resolve(ctx, []Dependency{mustParseDependency("reqwest[metrics]", below2)})
+		require.Error(t, err)
+	})
+}
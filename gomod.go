@@ -0,0 +1,337 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Go module version, ordered like golang.org/x/mod/semver.
+// Supports pseudo-versions and the +incompatible suffix.
+// https://go.dev/ref/mod#versions
+type GoModuleVersion struct {
+	major, minor, patch string
+	// including the leading "-"
+	prerelease string
+	// including the leading "+"
+	build string
+}
+
+var (
+	_ Version           = (*GoModuleVersion)(nil)
+	_ PrereleaseVersion = (*GoModuleVersion)(nil)
+)
+
+var GoModuleVersionScheme = VersionScheme{
+	Name:  "gomod",
+	Parse: ParseGoModuleVersion,
+}
+
+var (
+	goModuleVersionRegexp = regexp.MustCompile(
+		`^v(0|[1-9][0-9]*)(?:\.(0|[1-9][0-9]*)(?:\.(0|[1-9][0-9]*)` +
+			`(-[0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*)?(\+[0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*)?)?)?$`)
+	// from golang.org/x/mod/module/pseudo.go
+	goPseudoVersionRegexp = regexp.MustCompile(
+		`^v[0-9]+\.(0\.0-|\d+\.\d+-([^+]*\.)?0\.)\d{14}-[A-Za-z0-9]+(\+[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?$`)
+)
+
+const goPseudoVersionTimeFormat = "20060102150405"
+
+func MustGoModuleVersion(v string) *GoModuleVersion {
+	gv, err := NewGoModuleVersion(v)
+	if err != nil {
+		panic(err)
+	}
+	return gv
+}
+
+// NewGoModuleVersion parses a Go module version.
+// Like in golang.org/x/mod/semver, "v1" and "v1.2" are shorthands for "v1.0.0" and "v1.2.0".
+func NewGoModuleVersion(v string) (*GoModuleVersion, error) {
+	m := goModuleVersionRegexp.FindStringSubmatch(v)
+	if m == nil {
+		return nil, fmt.Errorf("invalid go module version %q", v)
+	}
+
+	gv := &GoModuleVersion{
+		major:      m[1],
+		minor:      m[2],
+		patch:      m[3],
+		prerelease: m[4],
+		build:      m[5],
+	}
+	if len(gv.minor) == 0 {
+		gv.minor = "0"
+	}
+	if len(gv.patch) == 0 {
+		gv.patch = "0"
+	}
+
+	if len(gv.build) != 0 && gv.build != "+incompatible" {
+		return nil, fmt.Errorf("invalid go module version %q: build metadata other than +incompatible", v)
+	}
+	if gv.IsIncompatible() && (gv.major == "0" || gv.major == "1") {
+		return nil, fmt.Errorf("invalid go module version %q: +incompatible requires major version >= 2", v)
+	}
+	return gv, nil
+}
+
+func ParseGoModuleVersion(v string) (Version, error) {
+	return NewGoModuleVersion(v)
+}
+
+func (gv *GoModuleVersion) Equal(v Version) bool {
+	otherGV, ok := v.(*GoModuleVersion)
+	if !ok || otherGV == nil {
+		return false
+	}
+	return gv.compare(otherGV) == 0
+}
+
+func (gv *GoModuleVersion) Less(v Version) bool {
+	otherGV, ok := v.(*GoModuleVersion)
+	if !ok || otherGV == nil {
+		return false
+	}
+	return gv.compare(otherGV) < 0
+}
+
+func (gv *GoModuleVersion) String() string {
+	return "v" + gv.major + "." + gv.minor + "." + gv.patch + gv.prerelease + gv.build
+}
+
+func (gv *GoModuleVersion) Scheme() string {
+	return GoModuleVersionScheme.Name
+}
+
+// Major returns the major version, e.g. "v2".
+func (gv *GoModuleVersion) Major() string {
+	return "v" + gv.major
+}
+
+func (gv *GoModuleVersion) IsPrerelease() bool {
+	return len(gv.prerelease) != 0
+}
+
+func (gv *GoModuleVersion) IsIncompatible() bool {
+	return gv.build == "+incompatible"
+}
+
+// IsPseudo returns true for pseudo-versions like v0.0.0-20230101120000-abcdef123456.
+func (gv *GoModuleVersion) IsPseudo() bool {
+	return goPseudoVersionRegexp.MatchString(gv.String())
+}
+
+// PseudoTime returns the commit time encoded in a pseudo-version.
+func (gv *GoModuleVersion) PseudoTime() (time.Time, error) {
+	fields, err := gv.pseudoFields()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(goPseudoVersionTimeFormat, fields[0])
+}
+
+// PseudoRevision returns the commit hash encoded in a pseudo-version.
+func (gv *GoModuleVersion) PseudoRevision() (string, error) {
+	fields, err := gv.pseudoFields()
+	if err != nil {
+		return "", err
+	}
+	return fields[1], nil
+}
+
+// Returns timestamp and revision of a pseudo-version.
+func (gv *GoModuleVersion) pseudoFields() ([]string, error) {
+	if !gv.IsPseudo() {
+		return nil, fmt.Errorf("%s is not a pseudo-version", gv)
+	}
+	// the last two dot or dash separated parts of the prerelease
+	// are the timestamp and the revision.
+	pre := gv.prerelease
+	j := strings.LastIndexByte(pre, '-')
+	i := strings.LastIndexAny(pre[:j], "-.")
+	return []string{pre[i+1 : j], pre[j+1:]}, nil
+}
+
+// Port of golang.org/x/mod/semver.Compare,
+// build metadata and thereby +incompatible is ignored.
+func (gv *GoModuleVersion) compare(other *GoModuleVersion) int {
+	if c := compareNumericIdentifier(gv.major, other.major); c != 0 {
+		return c
+	}
+	if c := compareNumericIdentifier(gv.minor, other.minor); c != 0 {
+		return c
+	}
+	if c := compareNumericIdentifier(gv.patch, other.patch); c != 0 {
+		return c
+	}
+	return compareSemverPrerelease(gv.prerelease, other.prerelease)
+}
+
+// Compares numbers of arbitrary length without leading zeros.
+func compareNumericIdentifier(a, b string) int {
+	if c := compareInt(len(a), len(b)); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
+}
+
+// Compares prereleases as specified by semver 2.0.0, including the leading "-".
+// Versions without prerelease sort after versions with one.
+func compareSemverPrerelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case len(a) == 0:
+		return 1
+	case len(b) == 0:
+		return -1
+	}
+
+	as, bs := strings.Split(a[1:], "."), strings.Split(b[1:], ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if as[i] == bs[i] {
+			continue
+		}
+		aNum, bNum := isNumericIdentifier(as[i]), isNumericIdentifier(bs[i])
+		switch {
+		case aNum && bNum:
+			return compareNumericIdentifier(as[i], bs[i])
+		case aNum:
+			return -1
+		case bNum:
+			return 1
+		default:
+			return strings.Compare(as[i], bs[i])
+		}
+	}
+	return compareInt(len(as), len(bs))
+}
+
+func isNumericIdentifier(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isASCIIDigit(s[i]) {
+			return false
+		}
+	}
+	return len(s) != 0
+}
+
+// CheckGoModulePathMajor checks that the major version suffix of a module path
+// matches the major version of the given version.
+// Each major version path, e.g. "example.com/foo/v2", is a distinct project.
+func CheckGoModulePathMajor(path string, v *GoModuleVersion) error {
+	_, suffix := SplitGoModulePath(path)
+
+	switch {
+	case len(suffix) == 0:
+		if v.major != "0" && v.major != "1" && !v.IsIncompatible() {
+			return fmt.Errorf("module %s: version %s needs major version suffix /%s", path, v, v.Major())
+		}
+	case strings.HasPrefix(path, "gopkg.in/"):
+		// gopkg.in/yaml.v2 allows v2.x.x and v2.x.x+incompatible
+		if strings.TrimPrefix(suffix, ".") != v.Major() {
+			return fmt.Errorf("module %s: version %s does not match major version suffix %s", path, v, suffix)
+		}
+	default:
+		if strings.TrimPrefix(suffix, "/") != v.Major() || v.IsIncompatible() {
+			return fmt.Errorf("module %s: version %s does not match major version suffix %s", path, v, suffix)
+		}
+	}
+	return nil
+}
+
+// SplitGoModulePath splits a module path into prefix and major version suffix,
+// e.g. "example.com/foo/v2" into "example.com/foo" and "/v2",
+// and "gopkg.in/yaml.v3" into "gopkg.in/yaml" and ".v3".
+func SplitGoModulePath(path string) (prefix, suffix string) {
+	sep := byte('/')
+	if strings.HasPrefix(path, "gopkg.in/") {
+		sep = '.'
+	}
+
+	i := strings.LastIndexByte(path, sep)
+	if i == -1 || i+2 >= len(path) || path[i+1] != 'v' {
+		return path, ""
+	}
+	n, err := strconv.Atoi(path[i+2:])
+	if err != nil || strconv.Itoa(n) != path[i+2:] || (sep == '/' && n < 2) {
+		return path, ""
+	}
+	return path[:i], path[i:]
+}
+
+// LoadGoMod parses the go.mod file of a module version into a ProjectVersion.
+// The returned name is the module path, including its major version suffix.
+// Each require directive is turned into a dependency,
+// that needs at least the required version, as in minimal version selection.
+// Other directives like replace and exclude are ignored.
+func LoadGoMod(data []byte, version string) (name string, pv ProjectVersion, err error) {
+	v, err := NewGoModuleVersion(version)
+	if err != nil {
+		return "", ProjectVersion{}, err
+	}
+	pv.Version = v
+
+	var inRequireBlock bool
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		if i := strings.Index(line, "//"); i != -1 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch {
+		case inRequireBlock && fields[0] == ")":
+			inRequireBlock = false
+			continue
+		case inRequireBlock:
+		case fields[0] == "module" && len(fields) == 2:
+			name = strings.Trim(fields[1], `"`)
+			continue
+		case fields[0] == "require" && len(fields) == 2 && fields[1] == "(":
+			inRequireBlock = true
+			continue
+		case fields[0] == "require":
+			fields = fields[1:]
+		default:
+			continue
+		}
+
+		if len(fields) != 2 {
+			return "", ProjectVersion{}, fmt.Errorf("go.mod:%d: invalid require %q", lineNo, line)
+		}
+		path := strings.Trim(fields[0], `"`)
+		reqV, err := NewGoModuleVersion(fields[1])
+		if err != nil {
+			return "", ProjectVersion{}, fmt.Errorf("go.mod:%d: %w", lineNo, err)
+		}
+		if err := CheckGoModulePathMajor(path, reqV); err != nil {
+			return "", ProjectVersion{}, fmt.Errorf("go.mod:%d: %w", lineNo, err)
+		}
+		pv.Dependencies = append(pv.Dependencies, Dependency{
+			Name:        path,
+			Constraints: []VersionConstraint{*NewConstraint(GreaterOrEqual, reqV)},
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return "", ProjectVersion{}, err
+	}
+
+	if len(name) == 0 {
+		return "", ProjectVersion{}, fmt.Errorf("go.mod: missing module directive")
+	}
+	if err := CheckGoModulePathMajor(name, v); err != nil {
+		return "", ProjectVersion{}, err
+	}
+	return name, pv, nil
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoModuleVersion(t *testing.T) {
+	gv, err := NewGoModuleVersion("v1.2")
+	require.NoError(t, err)
+
+	assert.Equal(t, "v1.2.0", gv.String())
+	assert.Equal(t, "gomod", gv.Scheme())
+	assert.Equal(t, "v1", gv.Major())
+
+	assert.False(t, gv.Equal(nil))
+	assert.True(t, gv.Equal(gv))
+	assert.True(t, gv.Equal(MustGoModuleVersion("v1.2.0")))
+	assert.False(t, gv.Equal(MustGoModuleVersion("v1.2.1")))
+
+	assert.False(t, gv.Less(nil))
+	assert.False(t, gv.Less(MustGoModuleVersion("v1.2.0-rc.1")))
+	assert.True(t, gv.Less(MustGoModuleVersion("v1.10.0")))
+
+	assert.Panics(t, func() {
+		MustGoModuleVersion("1.2.3")
+	})
+}
+
+func TestNewGoModuleVersion_invalid(t *testing.T) {
+	tests := map[string]string{
+		"1.2.3":               `invalid go module version "1.2.3"`,
+		"v1.02.3":             `invalid go module version "v1.02.3"`,
+		"v1.2-pre":            `invalid go module version "v1.2-pre"`,
+		"v1.2.3+meta":         `invalid go module version "v1.2.3+meta": build metadata other than +incompatible`,
+		"v1.2.3+incompatible": `invalid go module version "v1.2.3+incompatible": +incompatible requires major version >= 2`,
+	}
+	for v, expectedErr := range tests {
+		t.Run(v, func(t *testing.T) {
+			_, err := NewGoModuleVersion(v)
+			require.EqualError(t, err, expectedErr)
+		})
+	}
+}
+
+// Vectors from golang.org/x/mod/semver, without build metadata.
+func TestGoModuleVersion_ordering(t *testing.T) {
+	// groups of equal versions in ascending order
+	ordered := [][]string{
+		{"v0.0.0-20230101120000-abcdef123456"},
+		{"v0.0.0"},
+		{"v1.0.0-alpha"},
+		{"v1.0.0-alpha.1"},
+		{"v1.0.0-alpha.beta"},
+		{"v1.0.0-beta"},
+		{"v1.0.0-beta.2"},
+		{"v1.0.0-beta.11"},
+		{"v1.0.0-rc.1"},
+		{"v1", "v1.0", "v1.0.0"},
+		{"v1.2", "v1.2.0"},
+		{"v1.2.3-456"},
+		{"v1.2.3-456.789"},
+		{"v1.2.3-456-789"},
+		{"v1.2.3-456a"},
+		{"v1.2.3-pre"},
+		{"v1.2.3-pre.0.20230101120000-abcdef123456"},
+		{"v1.2.3-pre.1"},
+		{"v1.2.3-zzz"},
+		{"v1.2.3"},
+		{"v1.2.4-0.20230101120000-abcdef123456"},
+		{"v1.2.4"},
+		{"v2.0.0", "v2.0.0+incompatible"},
+		{"v2.0.1"},
+		{"v10.0.0"},
+	}
+	for i := range ordered {
+		for j := range ordered {
+			for _, av := range ordered[i] {
+				for _, bv := range ordered[j] {
+					a, b := MustGoModuleVersion(av), MustGoModuleVersion(bv)
+					assert.Equal(t, i < j, a.Less(b), "%s < %s", av, bv)
+					assert.Equal(t, i == j, a.Equal(b), "%s == %s", av, bv)
+				}
+			}
+		}
+	}
+}
+
+func TestGoModuleVersion_pseudo(t *testing.T) {
+	tests := []string{
+		"v0.0.0-20230101120000-abcdef123456",
+		"v1.2.3-pre.0.20230101120000-abcdef123456",
+		"v1.2.4-0.20230101120000-abcdef123456",
+		"v2.0.1-0.20230101120000-abcdef123456+incompatible",
+	}
+	for _, v := range tests {
+		t.Run(v, func(t *testing.T) {
+			gv := MustGoModuleVersion(v)
+			assert.True(t, gv.IsPseudo())
+
+			ts, err := gv.PseudoTime()
+			require.NoError(t, err)
+			assert.Equal(t, time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC), ts)
+
+			rev, err := gv.PseudoRevision()
+			require.NoError(t, err)
+			assert.Equal(t, "abcdef123456", rev)
+		})
+	}
+
+	gv := MustGoModuleVersion("v1.2.3-pre")
+	assert.False(t, gv.IsPseudo())
+	_, err := gv.PseudoTime()
+	require.EqualError(t, err, "v1.2.3-pre is not a pseudo-version")
+}
+
+func TestCheckGoModulePathMajor(t *testing.T) {
+	tests := []struct {
+		path, version, err string
+	}{
+		{path: "example.com/foo", version: "v0.1.0"},
+		{path: "example.com/foo", version: "v1.5.0"},
+		{path: "example.com/foo", version: "v3.0.0+incompatible"},
+		{path: "example.com/foo/v2", version: "v2.1.0"},
+		{path: "gopkg.in/yaml.v3", version: "v3.0.1"},
+		{path: "gopkg.in/check.v1", version: "v1.0.0-20201130134442-10cb98267c6c"},
+		{
+			path: "example.com/foo", version: "v2.0.0",
+			err: "module example.com/foo: version v2.0.0 needs major version suffix /v2",
+		},
+		{
+			path: "example.com/foo/v2", version: "v3.0.0",
+			err: "module example.com/foo/v2: version v3.0.0 does not match major version suffix /v2",
+		},
+		{
+			path: "example.com/foo/v2", version: "v2.0.0+incompatible",
+			err: "module example.com/foo/v2: version v2.0.0+incompatible does not match major version suffix /v2",
+		},
+		{
+			path: "gopkg.in/yaml.v3", version: "v2.4.0",
+			err: "module gopkg.in/yaml.v3: version v2.4.0 does not match major version suffix .v3",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.path+"@"+test.version, func(t *testing.T) {
+			err := CheckGoModulePathMajor(test.path, MustGoModuleVersion(test.version))
+			if len(test.err) == 0 {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestSplitGoModulePath(t *testing.T) {
+	tests := [][3]string{
+		{"example.com/foo", "example.com/foo", ""},
+		{"example.com/foo/v2", "example.com/foo", "/v2"},
+		{"example.com/foo/v1", "example.com/foo/v1", ""},
+		{"example.com/foo/v02", "example.com/foo/v02", ""},
+		{"gopkg.in/yaml.v3", "gopkg.in/yaml", ".v3"},
+	}
+	for _, test := range tests {
+		prefix, suffix := SplitGoModulePath(test[0])
+		assert.Equal(t, test[1], prefix, test[0])
+		assert.Equal(t, test[2], suffix, test[0])
+	}
+}
+
+func TestLoadGoMod(t *testing.T) {
+	name, pv, err := LoadGoMod([]byte(`module example.com/app/v2
+
+go 1.19
+
+require github.com/go-air/gini v1.0.4
+
+require (
+	github.com/Masterminds/semver/v3 v3.2.0
+	golang.org/x/mod v0.0.0-20230101120000-abcdef123456 // indirect
+)
+
+replace github.com/go-air/gini => ../gini
+`), "v2.3.0")
+	require.NoError(t, err)
+
+	assert.Equal(t, "example.com/app/v2", name)
+	assert.Equal(t, "v2.3.0", pv.Version.String())
+
+	var deps []string
+	for _, dep := range pv.Dependencies {
+		deps = append(deps, dep.Name+" "+ConstraintAND(dep.Constraints).String())
+	}
+	assert.Equal(t, []string{
+		"github.com/go-air/gini >=v1.0.4",
+		"github.com/Masterminds/semver/v3 >=v3.2.0",
+		"golang.org/x/mod >=v0.0.0-20230101120000-abcdef123456",
+	}, deps)
+}
+
+func TestLoadGoMod_resolve(t *testing.T) {
+	// go.mod files by module version, the /v2 module path is a separate project.
+	gomods := []struct{ version, gomod string }{
+		{"v1.0.0", "module example.com/app\nrequire (\n\texample.com/lib v1.1.0\n\texample.com/lib/v2 v2.0.0\n)\n"},
+		{"v1.0.0", "module example.com/lib\nrequire example.com/util v1.0.0\n"},
+		{"v1.1.0", "module example.com/lib\nrequire example.com/util v1.1.0\n"},
+		{"v2.0.0", "module example.com/lib/v2\nrequire example.com/util v1.0.0\n"},
+		{"v1.0.0", "module example.com/util\n"},
+		{"v1.1.0", "module example.com/util\n"},
+	}
+
+	ctx := context.Background()
+	projects := map[string]*Project{}
+	for _, m := range gomods {
+		name, pv, err := LoadGoMod([]byte(m.gomod), m.version)
+		require.NoError(t, err)
+		if _, ok := projects[name]; !ok {
+			projects[name] = &Project{Name: name, Scheme: GoModuleVersionScheme.Name}
+		}
+		projects[name].Versions = append(projects[name].Versions, pv)
+	}
+	db := NewInMemoryDB()
+	for _, project := range projects {
+		require.NoError(t, db.Add(ctx, *project))
+	}
+
+	r := NewResolver(db)
+	result, err := r.Resolve(ctx, []Dependency{{Name: "example.com/app"}})
+	require.NoError(t, err)
+
+	var resolved []string
+	for _, rpv := range result {
+		resolved = append(resolved, rpv.String())
+	}
+	sort.Strings(resolved)
+	// the highest required util version wins, like in minimal version selection.
+	assert.Equal(t, []string{
+		"example.com/app=v1.0.0",
+		"example.com/lib/v2=v2.0.0",
+		"example.com/lib=v1.1.0",
+		"example.com/util=v1.1.0",
+	}, resolved)
+}
+
+func TestLoadGoMod_invalid(t *testing.T) {
+	tests := []struct {
+		name, gomod, version, err string
+	}{
+		{
+			name: "missing module", gomod: "go 1.19\n", version: "v1.0.0",
+			err: "go.mod: missing module directive",
+		},
+		{
+			name: "module major", gomod: "module example.com/app\n", version: "v2.0.0",
+			err: "module example.com/app: version v2.0.0 needs major version suffix /v2",
+		},
+		{
+			name: "require major", gomod: "module example.com/app\nrequire example.com/lib/v2 v1.0.0\n", version: "v1.0.0",
+			err: "go.mod:2: module example.com/lib/v2: version v1.0.0 does not match major version suffix /v2",
+		},
+		{
+			name: "require version", gomod: "module example.com/app\nrequire (\n\texample.com/lib 1.0.0\n)\n", version: "v1.0.0",
+			err: `go.mod:3: invalid go module version "1.0.0"`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := LoadGoMod([]byte(test.gomod), test.version)
+			require.EqualError(t, err, test.err)
+		})
+	}
+}
+
+func FuzzNewGoModuleVersion(f *testing.F) {
+	fuzzVersionRoundTrip(f, ParseGoModuleVersion,
+		"v1.2", "v2.0.0+incompatible", "v0.0.0-20230101120000-abcdef123456", "v1.2.3-pre.1")
+}
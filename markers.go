@@ -0,0 +1,414 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Target environment of a resolution, e.g. {"os": "linux", "arch": "amd64"}.
+type Environment map[string]string
+
+func (e Environment) String() string {
+	keys := make([]string, 0, len(e))
+	for key := range e {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var s []string
+	for _, key := range keys {
+		s = append(s, key+"="+e[key])
+	}
+	return strings.Join(s, ",")
+}
+
+// Environment marker expression, that decides whether a dependency applies to an Environment.
+//
+// Grammar:
+//
+//	expr       = and { "||" and }
+//	and        = unary { "&&" unary }
+//	unary      = "!" unary | "(" expr ")" | comparison
+//	comparison = key ( "==" | "!=" ) value
+//	           | key [ "not" ] "in" "[" value { "," value } "]"
+//
+// Keys are identifiers like os or arch, values are quoted strings.
+// Keys missing from the Environment compare as the empty string.
+type Marker interface {
+	Matches(env Environment) bool
+	String() string
+}
+
+// ParseMarker parses an environment marker expression,
+// e.g. `os == "linux" && arch in ["amd64", "arm64"]`.
+func ParseMarker(s string) (Marker, error) {
+	tokens, err := tokenizeMarker(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid marker %q: %w", s, err)
+	}
+	p := &markerParser{tokens: tokens}
+	m, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid marker %q: %w", s, err)
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("invalid marker %q: unexpected %s", s, p.tokens[p.pos])
+	}
+	return m, nil
+}
+
+// MustMarker parses the given marker expression and panics on error.
+func MustMarker(s string) Marker {
+	m, err := ParseMarker(s)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+type markerCompare struct {
+	key    string
+	negate bool
+	value  string
+}
+
+func (m markerCompare) Matches(env Environment) bool {
+	return (env[m.key] == m.value) != m.negate
+}
+
+func (m markerCompare) String() string {
+	op := "=="
+	if m.negate {
+		op = "!="
+	}
+	return fmt.Sprintf("%s %s %s", m.key, op, strconv.Quote(m.value))
+}
+
+type markerIn struct {
+	key    string
+	negate bool
+	values []string
+}
+
+func (m markerIn) Matches(env Environment) bool {
+	for _, value := range m.values {
+		if env[m.key] == value {
+			return !m.negate
+		}
+	}
+	return m.negate
+}
+
+func (m markerIn) String() string {
+	op := "in"
+	if m.negate {
+		op = "not in"
+	}
+	values := make([]string, len(m.values))
+	for i, value := range m.values {
+		values[i] = strconv.Quote(value)
+	}
+	return fmt.Sprintf("%s %s [%s]", m.key, op, strings.Join(values, ", "))
+}
+
+type markerNot struct {
+	marker Marker
+}
+
+func (m markerNot) Matches(env Environment) bool {
+	return !m.marker.Matches(env)
+}
+
+func (m markerNot) String() string {
+	switch m.marker.(type) {
+	case markerCompare, markerIn, markerNot:
+		return "!" + m.marker.String()
+	default:
+		return "!(" + m.marker.String() + ")"
+	}
+}
+
+type markerAnd []Marker
+
+func (m markerAnd) Matches(env Environment) bool {
+	for _, marker := range m {
+		if !marker.Matches(env) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m markerAnd) String() string {
+	s := make([]string, len(m))
+	for i, marker := range m {
+		if _, ok := marker.(markerOr); ok {
+			s[i] = "(" + marker.String() + ")"
+			continue
+		}
+		s[i] = marker.String()
+	}
+	return strings.Join(s, " && ")
+}
+
+type markerOr []Marker
+
+func (m markerOr) Matches(env Environment) bool {
+	for _, marker := range m {
+		if marker.Matches(env) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m markerOr) String() string {
+	s := make([]string, len(m))
+	for i, marker := range m {
+		s[i] = marker.String()
+	}
+	return strings.Join(s, " || ")
+}
+
+type markerTokenKind int
+
+const (
+	markerTokenIdent markerTokenKind = iota
+	markerTokenString
+	markerTokenSymbol
+)
+
+type markerToken struct {
+	kind  markerTokenKind
+	value string
+}
+
+func (t markerToken) String() string {
+	if t.kind == markerTokenString {
+		return strconv.Quote(t.value)
+	}
+	return fmt.Sprintf("%q", t.value)
+}
+
+var markerSymbols = []string{"==", "!=", "&&", "||", "!", "(", ")", "[", "]", ","}
+
+func tokenizeMarker(s string) ([]markerToken, error) {
+	var tokens []markerToken
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+
+		case c == '"' || c == '\'':
+			end := i + 1
+			for end < len(s) && s[end] != c {
+				if s[end] == '\\' && c == '"' {
+					end++
+				}
+				end++
+			}
+			if end >= len(s) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			value := s[i+1 : end]
+			if c == '"' {
+				var err error
+				if value, err = strconv.Unquote(s[i : end+1]); err != nil {
+					return nil, fmt.Errorf("invalid string %s", s[i:end+1])
+				}
+			}
+			tokens = append(tokens, markerToken{kind: markerTokenString, value: value})
+			i = end + 1
+
+		case isMarkerIdentStart(c):
+			end := i + 1
+			for end < len(s) && (isMarkerIdentStart(s[end]) || s[end] == '.' || ('0' <= s[end] && s[end] <= '9')) {
+				end++
+			}
+			tokens = append(tokens, markerToken{kind: markerTokenIdent, value: s[i:end]})
+			i = end
+
+		default:
+			var symbol string
+			for _, sym := range markerSymbols {
+				if strings.HasPrefix(s[i:], sym) {
+					symbol = sym
+					break
+				}
+			}
+			if len(symbol) == 0 {
+				return nil, fmt.Errorf("unexpected %q", c)
+			}
+			tokens = append(tokens, markerToken{kind: markerTokenSymbol, value: symbol})
+			i += len(symbol)
+		}
+	}
+	return tokens, nil
+}
+
+func isMarkerIdentStart(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+type markerParser struct {
+	tokens []markerToken
+	pos    int
+}
+
+func (p *markerParser) peek(kind markerTokenKind, value string) bool {
+	return p.pos < len(p.tokens) &&
+		p.tokens[p.pos].kind == kind && p.tokens[p.pos].value == value
+}
+
+func (p *markerParser) next() (markerToken, error) {
+	if p.pos == len(p.tokens) {
+		return markerToken{}, fmt.Errorf("unexpected end")
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	return t, nil
+}
+
+func (p *markerParser) expect(value string) error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if t.kind != markerTokenSymbol || t.value != value {
+		return fmt.Errorf("expected %q, got %s", value, t)
+	}
+	return nil
+}
+
+func (p *markerParser) parseOr() (Marker, error) {
+	var or markerOr
+	for {
+		m, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		or = append(or, m)
+		if !p.peek(markerTokenSymbol, "||") {
+			break
+		}
+		p.pos++
+	}
+	if len(or) == 1 {
+		return or[0], nil
+	}
+	return or, nil
+}
+
+func (p *markerParser) parseAnd() (Marker, error) {
+	var and markerAnd
+	for {
+		m, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, m)
+		if !p.peek(markerTokenSymbol, "&&") {
+			break
+		}
+		p.pos++
+	}
+	if len(and) == 1 {
+		return and[0], nil
+	}
+	return and, nil
+}
+
+func (p *markerParser) parseUnary() (Marker, error) {
+	switch {
+	case p.peek(markerTokenSymbol, "!"):
+		p.pos++
+		m, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return markerNot{marker: m}, nil
+
+	case p.peek(markerTokenSymbol, "("):
+		p.pos++
+		m, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return m, p.expect(")")
+	}
+	return p.parseComparison()
+}
+
+func (p *markerParser) parseComparison() (Marker, error) {
+	key, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if key.kind != markerTokenIdent {
+		return nil, fmt.Errorf("expected key, got %s", key)
+	}
+
+	op, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case op.kind == markerTokenSymbol && (op.value == "==" || op.value == "!="):
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		return markerCompare{key: key.value, negate: op.value == "!=", value: value}, nil
+
+	case op.kind == markerTokenIdent && op.value == "not":
+		if !p.peek(markerTokenIdent, "in") {
+			return nil, fmt.Errorf(`expected "in" after "not"`)
+		}
+		p.pos++
+		values, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		return markerIn{key: key.value, negate: true, values: values}, nil
+
+	case op.kind == markerTokenIdent && op.value == "in":
+		values, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		return markerIn{key: key.value, values: values}, nil
+	}
+	return nil, fmt.Errorf("expected operator, got %s", op)
+}
+
+func (p *markerParser) parseList() ([]string, error) {
+	if err := p.expect("["); err != nil {
+		return nil, err
+	}
+	var values []string
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		if !p.peek(markerTokenSymbol, ",") {
+			break
+		}
+		p.pos++
+	}
+	return values, p.expect("]")
+}
+
+func (p *markerParser) parseValue() (string, error) {
+	t, err := p.next()
+	if err != nil {
+		return "", err
+	}
+	if t.kind != markerTokenString {
+		return "", fmt.Errorf("expected quoted value, got %s", t)
+	}
+	return t.value, nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMarker(t *testing.T) {
+	var (
+		linuxAMD64  = Environment{"os": "linux", "arch": "amd64"}
+		linuxARM64  = Environment{"os": "linux", "arch": "arm64"}
+		darwinARM64 = Environment{"os": "darwin", "arch": "arm64"}
+		windows386  = Environment{"os": "windows", "arch": "386"}
+	)
+	environments := []Environment{linuxAMD64, linuxARM64, darwinARM64, windows386}
+
+	tests := []struct {
+		marker   string
+		str      string
+		matching []Environment
+	}{
+		{
+			marker:   `os == "linux"`,
+			str:      `os == "linux"`,
+			matching: []Environment{linuxAMD64, linuxARM64},
+		},
+		{
+			marker:   `os != 'linux'`,
+			str:      `os != "linux"`,
+			matching: []Environment{darwinARM64, windows386},
+		},
+		{
+			marker:   `os == "linux" && arch in ["amd64","arm64"]`,
+			str:      `os == "linux" && arch in ["amd64", "arm64"]`,
+			matching: []Environment{linuxAMD64, linuxARM64},
+		},
+		{
+			marker:   `arch not in ["amd64"]`,
+			str:      `arch not in ["amd64"]`,
+			matching: []Environment{linuxARM64, darwinARM64, windows386},
+		},
+		{
+			marker:   `os == "darwin" || os == "windows" && arch == "386"`,
+			str:      `os == "darwin" || os == "windows" && arch == "386"`,
+			matching: []Environment{darwinARM64, windows386},
+		},
+		{
+			marker:   `(os == "darwin" || os == "linux") && arch == "arm64"`,
+			str:      `(os == "darwin" || os == "linux") && arch == "arm64"`,
+			matching: []Environment{linuxARM64, darwinARM64},
+		},
+		{
+			marker:   `!(os == "linux" || os == "darwin")`,
+			str:      `!(os == "linux" || os == "darwin")`,
+			matching: []Environment{windows386},
+		},
+		{
+			marker:   `!os == "linux"`,
+			str:      `!os == "linux"`,
+			matching: []Environment{darwinARM64, windows386},
+		},
+		{
+			marker: `libc == "musl"`,
+			str:    `libc == "musl"`,
+		},
+		{
+			marker:   `libc == ""`,
+			str:      `libc == ""`,
+			matching: environments,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.marker, func(t *testing.T) {
+			m, err := ParseMarker(test.marker)
+			require.NoError(t, err)
+			assert.Equal(t, test.str, m.String())
+
+			var matching []Environment
+			for _, env := range environments {
+				if m.Matches(env) {
+					matching = append(matching, env)
+				}
+			}
+			assert.Equal(t, test.matching, matching)
+		})
+	}
+}
+
+func TestParseMarker_invalid(t *testing.T) {
+	tests := map[string]string{
+		``:                          `invalid marker "": unexpected end`,
+		`os`:                        `invalid marker "os": unexpected end`,
+		`os = "linux"`:              `invalid marker "os = \"linux\"": unexpected '='`,
+		`os == linux`:               `invalid marker "os == linux": expected quoted value, got "linux"`,
+		`"linux" == os`:             `invalid marker "\"linux\" == os": expected key, got "linux"`,
+		`os == "linux`:              `invalid marker "os == \"linux": unterminated string`,
+		`os not ["linux"]`:          `invalid marker "os not [\"linux\"]": expected "in" after "not"`,
+		`arch in ["amd64"`:          `invalid marker "arch in [\"amd64\"": unexpected end`,
+		`(os == "linux"`:            `invalid marker "(os == \"linux\"": unexpected end`,
+		`os == "linux")`:            `invalid marker "os == \"linux\")": unexpected ")"`,
+		`os == "linux" arch`:        `invalid marker "os == \"linux\" arch": unexpected "arch"`,
+		`os == "a" && || os == "b"`: `invalid marker "os == \"a\" && || os == \"b\"": expected key, got "||"`,
+	}
+	for marker, expectedErr := range tests {
+		t.Run(marker, func(t *testing.T) {
+			_, err := ParseMarker(marker)
+			require.EqualError(t, err, expectedErr)
+		})
+	}
+}
+
+func TestEnvironment_String(t *testing.T) {
+	assert.Equal(t, "arch=amd64,os=linux", Environment{"os": "linux", "arch": "amd64"}.String())
+}
+
+// Checks that parsing never panics and that parsing the String of a marker yields the same String.
+func FuzzParseMarker(f *testing.F) {
+	for _, seed := range []string{
+		`os == "linux" && arch in ["amd64", "arm64"]`,
+		`!(os == "darwin" || os == 'windows')`,
+		`arch not in ["386"]`,
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		m, err := ParseMarker(s)
+		if err != nil {
+			return
+		}
+		reparsed, err := ParseMarker(m.String())
+		require.NoError(t, err, m.String())
+		assert.Equal(t, m.String(), reparsed.String())
+	})
+}
@@ -0,0 +1,535 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Maven artifact version, ordered like Maven's ComparableVersion.
+// https://maven.apache.org/pom.html#version-order-specification
+type MavenVersion struct {
+	original string
+	items    *mavenListItem
+}
+
+var (
+	_ Version           = (*MavenVersion)(nil)
+	_ PrereleaseVersion = (*MavenVersion)(nil)
+)
+
+var MavenVersionScheme = VersionScheme{
+	Name:  "maven",
+	Parse: ParseMavenVersion,
+	ParseConstraint: func(constraint string) (VersionConstraint, error) {
+		return ParseMavenVersionRange(constraint)
+	},
+}
+
+func MustMavenVersion(v string) *MavenVersion {
+	mv, err := NewMavenVersion(v)
+	if err != nil {
+		panic(err)
+	}
+	return mv
+}
+
+func NewMavenVersion(v string) (*MavenVersion, error) {
+	v = strings.TrimSpace(v)
+	if len(v) == 0 {
+		return nil, fmt.Errorf("invalid maven version: empty")
+	}
+	if strings.ContainsAny(v, "[](), ") {
+		return nil, fmt.Errorf("invalid maven version %q", v)
+	}
+	return &MavenVersion{
+		original: v,
+		items:    parseMavenItems(strings.ToLower(v)),
+	}, nil
+}
+
+func ParseMavenVersion(v string) (Version, error) {
+	return NewMavenVersion(v)
+}
+
+func (mv *MavenVersion) Equal(v Version) bool {
+	otherMV, ok := v.(*MavenVersion)
+	if !ok || otherMV == nil {
+		return false
+	}
+	return mv.items.compareTo(otherMV.items) == 0
+}
+
+func (mv *MavenVersion) Less(v Version) bool {
+	otherMV, ok := v.(*MavenVersion)
+	if !ok || otherMV == nil {
+		return false
+	}
+	return mv.items.compareTo(otherMV.items) < 0
+}
+
+func (mv *MavenVersion) String() string {
+	return mv.original
+}
+
+func (mv *MavenVersion) Scheme() string {
+	return MavenVersionScheme.Name
+}
+
+// IsPrerelease returns true for versions with a qualifier
+// ordered before the release, like alpha, beta, milestone, rc or snapshot.
+func (mv *MavenVersion) IsPrerelease() bool {
+	return mv.items.compareTo(parseMavenItems(mv.releaseVersion())) < 0
+}
+
+// Version up to the first qualifier.
+func (mv *MavenVersion) releaseVersion() string {
+	v := strings.ToLower(mv.original)
+	for i, r := range v {
+		if !isASCIIDigit(byte(r)) && r != '.' {
+			return v[:i]
+		}
+	}
+	return v
+}
+
+// Port of ComparableVersion.parseVersion.
+// Versions are split into a tree of items at ".", "-"
+// and at transitions between digits and letters.
+func parseMavenItems(v string) *mavenListItem {
+	list := &mavenListItem{}
+	stack := []*mavenListItem{list}
+	pushList := func() {
+		next := &mavenListItem{}
+		list.items = append(list.items, next)
+		list = next
+		stack = append(stack, list)
+	}
+
+	isDigit := false
+	start := 0
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		switch {
+		case c == '.' || c == '-':
+			if i == start {
+				list.items = append(list.items, mavenIntItem(""))
+			} else {
+				list.items = append(list.items, parseMavenItem(isDigit, v[start:i]))
+			}
+			start = i + 1
+			if c == '-' {
+				pushList()
+			}
+
+		case isASCIIDigit(c):
+			if !isDigit && i > start {
+				list.items = append(list.items, newMavenStringItem(v[start:i], true))
+				start = i
+				pushList()
+			}
+			isDigit = true
+
+		default:
+			if isDigit && i > start {
+				list.items = append(list.items, parseMavenItem(true, v[start:i]))
+				start = i
+				pushList()
+			}
+			isDigit = false
+		}
+	}
+	if len(v) > start {
+		list.items = append(list.items, parseMavenItem(isDigit, v[start:]))
+	}
+
+	for i := len(stack) - 1; i >= 0; i-- {
+		stack[i].normalize()
+	}
+	return stack[0]
+}
+
+func parseMavenItem(isDigit bool, s string) mavenItem {
+	if isDigit {
+		return mavenIntItem(strings.TrimLeft(s, "0"))
+	}
+	return newMavenStringItem(s, false)
+}
+
+// Item of a parsed maven version.
+// compareTo is called with nil, when the other version has no item at this position.
+type mavenItem interface {
+	compareTo(other mavenItem) int
+	isNull() bool
+}
+
+// Number without leading zeros, kept as string to support arbitrary length.
+type mavenIntItem string
+
+func (i mavenIntItem) compareTo(other mavenItem) int {
+	switch o := other.(type) {
+	case nil:
+		if i.isNull() {
+			return 0
+		}
+		return 1
+	case mavenIntItem:
+		if c := compareInt(len(i), len(o)); c != 0 {
+			return c
+		}
+		return strings.Compare(string(i), string(o))
+	default:
+		// 1.1 > 1-sp and 1.1 > 1-1
+		return 1
+	}
+}
+
+func (i mavenIntItem) isNull() bool {
+	return len(i) == 0
+}
+
+// Well known qualifiers in ascending order,
+// the empty string is the release itself.
+var mavenQualifiers = []string{"alpha", "beta", "milestone", "rc", "snapshot", "", "sp"}
+
+var mavenQualifierAliases = map[string]string{
+	"ga":      "",
+	"final":   "",
+	"release": "",
+	"cr":      "rc",
+}
+
+const mavenReleaseQualifierIndex = "5"
+
+type mavenStringItem string
+
+func newMavenStringItem(s string, followedByDigit bool) mavenStringItem {
+	if followedByDigit && len(s) == 1 {
+		switch s {
+		case "a":
+			s = "alpha"
+		case "b":
+			s = "beta"
+		case "m":
+			s = "milestone"
+		}
+	}
+	if alias, ok := mavenQualifierAliases[s]; ok {
+		s = alias
+	}
+	return mavenStringItem(s)
+}
+
+// Known qualifiers are ordered by their index,
+// unknown qualifiers come after all known ones, in lexical order.
+func (s mavenStringItem) comparable() string {
+	for i, q := range mavenQualifiers {
+		if q == string(s) {
+			return fmt.Sprint(i)
+		}
+	}
+	return fmt.Sprintf("%d-%s", len(mavenQualifiers), s)
+}
+
+func (s mavenStringItem) compareTo(other mavenItem) int {
+	switch o := other.(type) {
+	case nil:
+		// 1-rc < 1, 1-ga == 1
+		return strings.Compare(s.comparable(), mavenReleaseQualifierIndex)
+	case mavenStringItem:
+		return strings.Compare(s.comparable(), o.comparable())
+	default:
+		// 1.any < 1.1 and 1-any < 1-1
+		return -1
+	}
+}
+
+func (s mavenStringItem) isNull() bool {
+	return s.comparable() == mavenReleaseQualifierIndex
+}
+
+type mavenListItem struct {
+	items []mavenItem
+}
+
+func (l *mavenListItem) compareTo(other mavenItem) int {
+	switch o := other.(type) {
+	case nil:
+		// every item counts since Maven 3.8 (MNG-6964): 1-0.1 > 1 and 1-0.alpha < 1
+		for _, item := range l.items {
+			if c := item.compareTo(nil); c != 0 {
+				return c
+			}
+		}
+		return 0
+	case mavenIntItem:
+		// 1-1 < 1.0.x
+		return -1
+	case mavenStringItem:
+		// 1-1 > 1-sp
+		return 1
+	case *mavenListItem:
+		for i := 0; i < len(l.items) || i < len(o.items); i++ {
+			var left, right mavenItem
+			if i < len(l.items) {
+				left = l.items[i]
+			}
+			if i < len(o.items) {
+				right = o.items[i]
+			}
+
+			var c int
+			switch {
+			case left == nil && right == nil:
+				c = 0
+			case left == nil:
+				c = -right.compareTo(nil)
+			default:
+				c = left.compareTo(right)
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	default:
+		return 0
+	}
+}
+
+func (l *mavenListItem) isNull() bool {
+	return len(l.items) == 0
+}
+
+// Removes trailing null items: 0, "" and empty lists.
+func (l *mavenListItem) normalize() {
+	for i := len(l.items) - 1; i >= 0; i-- {
+		if l.items[i].isNull() {
+			l.items = append(l.items[:i], l.items[i+1:]...)
+		} else if _, ok := l.items[i].(*mavenListItem); !ok {
+			break
+		}
+	}
+}
+
+// Maven version range, e.g. "[1.0,2.0)".
+type mavenRange struct {
+	lower, upper                   *MavenVersion
+	lowerInclusive, upperInclusive bool
+	// soft requirement, e.g. "1.0", matches every version.
+	soft *MavenVersion
+}
+
+var (
+	_ VersionConstraint = mavenRange{}
+	_ ExactConstraint   = mavenRange{}
+)
+
+// ParseMavenVersionRange parses Maven version range syntax into a constraint tree.
+//
+//	1.0            soft requirement on 1.0, matches any version
+//	[1.0]          exactly 1.0
+//	(,1.0]         x <= 1.0
+//	[1.2,1.3]      1.2 <= x <= 1.3
+//	[1.0,2.0)      1.0 <= x < 2.0
+//	[1.5,)         x >= 1.5
+//	(,1.0],[1.2,)  x <= 1.0 or x >= 1.2
+func ParseMavenVersionRange(spec string) (VersionConstraint, error) {
+	rest := strings.ReplaceAll(spec, " ", "")
+	if len(rest) == 0 {
+		return nil, fmt.Errorf("invalid maven version range: empty")
+	}
+	if rest[0] != '[' && rest[0] != '(' {
+		v, err := NewMavenVersion(rest)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maven version range %q: %w", spec, err)
+		}
+		return mavenRange{soft: v}, nil
+	}
+
+	var ranges mavenRanges
+	for len(rest) > 0 {
+		end := strings.IndexAny(rest, "])")
+		if end == -1 {
+			return nil, fmt.Errorf("invalid maven version range %q: missing closing bracket", spec)
+		}
+		r, err := parseMavenRange(rest[:end+1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid maven version range %q: %w", spec, err)
+		}
+		ranges = append(ranges, r)
+
+		rest = rest[end+1:]
+		if len(rest) > 0 {
+			if rest[0] != ',' || len(rest) == 1 {
+				return nil, fmt.Errorf("invalid maven version range %q: expected ',' between ranges", spec)
+			}
+			rest = rest[1:]
+		}
+	}
+
+	if len(ranges) == 1 {
+		return ranges[0], nil
+	}
+	return ranges, nil
+}
+
+func parseMavenRange(s string) (mavenRange, error) {
+	r := mavenRange{
+		lowerInclusive: s[0] == '[',
+		upperInclusive: s[len(s)-1] == ']',
+	}
+	if s[0] != '[' && s[0] != '(' {
+		return mavenRange{}, fmt.Errorf("range %q must start with '[' or '('", s)
+	}
+
+	bounds := strings.Split(s[1:len(s)-1], ",")
+	switch len(bounds) {
+	case 1:
+		if !r.lowerInclusive || !r.upperInclusive {
+			return mavenRange{}, fmt.Errorf("single version range %q must be inclusive", s)
+		}
+		v, err := NewMavenVersion(bounds[0])
+		if err != nil {
+			return mavenRange{}, err
+		}
+		r.lower, r.upper = v, v
+		return r, nil
+
+	case 2:
+		for i, b := range bounds {
+			if len(b) == 0 {
+				continue
+			}
+			v, err := NewMavenVersion(b)
+			if err != nil {
+				return mavenRange{}, err
+			}
+			if i == 0 {
+				r.lower = v
+			} else {
+				r.upper = v
+			}
+		}
+		if r.lower == nil && r.lowerInclusive || r.upper == nil && r.upperInclusive {
+			return mavenRange{}, fmt.Errorf("unbounded range %q must be exclusive", s)
+		}
+		if r.lower != nil && r.upper != nil && r.upper.Less(r.lower) {
+			return mavenRange{}, fmt.Errorf("range %q lower bound is greater than upper bound", s)
+		}
+		return r, nil
+
+	default:
+		return mavenRange{}, fmt.Errorf("range %q must have one or two bounds", s)
+	}
+}
+
+func (r mavenRange) Matches(v Version) bool {
+	mv, ok := v.(*MavenVersion)
+	if !ok || mv == nil {
+		return false
+	}
+	if r.soft != nil {
+		return true
+	}
+
+	if r.lower != nil {
+		c := mv.items.compareTo(r.lower.items)
+		if c < 0 || c == 0 && !r.lowerInclusive {
+			return false
+		}
+	}
+	if r.upper != nil {
+		c := mv.items.compareTo(r.upper.items)
+		if c > 0 || c == 0 && !r.upperInclusive {
+			return false
+		}
+	}
+	return true
+}
+
+func (r mavenRange) String() string {
+	if r.soft != nil {
+		return r.soft.String()
+	}
+	if r.lower != nil && r.lower == r.upper {
+		return "[" + r.lower.String() + "]"
+	}
+
+	var b strings.Builder
+	if r.lowerInclusive {
+		b.WriteByte('[')
+	} else {
+		b.WriteByte('(')
+	}
+	if r.lower != nil {
+		b.WriteString(r.lower.String())
+	}
+	b.WriteByte(',')
+	if r.upper != nil {
+		b.WriteString(r.upper.String())
+	}
+	if r.upperInclusive {
+		b.WriteByte(']')
+	} else {
+		b.WriteByte(')')
+	}
+	return b.String()
+}
+
+func (r mavenRange) Versions() []Version {
+	var versions []Version
+	for _, v := range []*MavenVersion{r.soft, r.lower, r.upper} {
+		if v != nil && (v != r.upper || r.upper != r.lower) {
+			versions = append(versions, v)
+		}
+	}
+	return versions
+}
+
+// Single version ranges like "[1.0]" are exact.
+func (r mavenRange) ExactVersion() (Version, bool) {
+	if r.lower == nil || r.lower != r.upper {
+		return nil, false
+	}
+	return r.lower, true
+}
+
+// Union of maven version ranges, e.g. "(,1.0],[1.2,)".
+type mavenRanges []mavenRange
+
+var (
+	_ VersionConstraint = mavenRanges{}
+	_ ExactConstraint   = mavenRanges{}
+)
+
+func (rs mavenRanges) Matches(v Version) bool {
+	for _, r := range rs {
+		if r.Matches(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (rs mavenRanges) String() string {
+	s := make([]string, len(rs))
+	for i, r := range rs {
+		s[i] = r.String()
+	}
+	return strings.Join(s, ",")
+}
+
+func (rs mavenRanges) Versions() []Version {
+	var versions []Version
+	for _, r := range rs {
+		versions = append(versions, r.Versions()...)
+	}
+	return versions
+}
+
+func (rs mavenRanges) ExactVersion() (Version, bool) {
+	if len(rs) != 1 {
+		return nil, false
+	}
+	return rs[0].ExactVersion()
+}
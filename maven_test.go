@@ -0,0 +1,161 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMavenVersion(t *testing.T) {
+	mv, err := NewMavenVersion("1.0-SNAPSHOT")
+	require.NoError(t, err)
+
+	assert.Equal(t, "1.0-SNAPSHOT", mv.String())
+	assert.Equal(t, "maven", mv.Scheme())
+	assert.True(t, mv.IsPrerelease())
+	assert.False(t, MustMavenVersion("1.0-sp1").IsPrerelease())
+
+	assert.False(t, mv.Equal(nil))
+	assert.True(t, mv.Equal(mv))
+	assert.False(t, mv.Equal(MustMavenVersion("1.0")))
+
+	assert.False(t, mv.Less(nil))
+	assert.False(t, mv.Less(MustMavenVersion("1.0-rc1")))
+	assert.True(t, mv.Less(MustMavenVersion("1.0")))
+
+	assert.Panics(t, func() {
+		MustMavenVersion("[1.0]")
+	})
+}
+
+// Vectors from Maven's ComparableVersionTest.
+func TestMavenVersion_ordering(t *testing.T) {
+	tests := map[string][]string{
+		"qualifiers": {
+			"1-alpha2snapshot", "1-alpha2", "1-alpha-123", "1-beta-2", "1-beta123",
+			"1-m2", "1-m11", "1-rc", "1-cr2", "1-rc123", "1-SNAPSHOT", "1", "1-sp",
+			"1-sp2", "1-sp123", "1-abc", "1-def", "1-pom-1", "1-1-snapshot", "1-1",
+			"1-2", "1-123",
+		},
+		"numbers": {
+			"2.0", "2-1", "2.0.a", "2.0.0.a", "2.0.2", "2.0.123", "2.1.0", "2.1-a",
+			"2.1b", "2.1-c", "2.1-1", "2.1.0.1", "2.2", "2.123", "11.a2", "11.a11",
+			"11.b2", "11.b11", "11.m2", "11.m11", "11", "11.a", "11b", "11c", "11m",
+		},
+		"padded lists": {"1.0-0.alpha", "1", "1-0.1"},
+	}
+	for name, ordered := range tests {
+		t.Run(name, func(t *testing.T) {
+			for i := range ordered {
+				for j := range ordered {
+					a, b := MustMavenVersion(ordered[i]), MustMavenVersion(ordered[j])
+					assert.Equal(t, i < j, a.Less(b), "%s < %s", a, b)
+					assert.Equal(t, i == j, a.Equal(b), "%s == %s", a, b)
+				}
+			}
+		})
+	}
+}
+
+func TestMavenVersion_equal(t *testing.T) {
+	equal := [][]string{
+		{"1", "1.0", "1.0.0", "1-0", "1.0-0", "1ga", "1-ga", "1.0-final", "1.RELEASE", "1-GA"},
+		{"1a1", "1-a1", "1-alpha-1", "1.0-alpha1", "1alpha1"},
+		{"1b2", "1-b2", "1-beta-2", "1.0-beta2"},
+		{"1m3", "1-m3", "1-milestone-3", "1.0-milestone3"},
+		{"1-cr1", "1-rc1", "1.0-rc-1"},
+		{"1x", "1-x", "1.0.0-x"},
+		{"1.0.0000000000000000000000000001", "1.0.1"},
+	}
+	for _, versions := range equal {
+		for _, a := range versions {
+			for _, b := range versions {
+				assert.True(t, MustMavenVersion(a).Equal(MustMavenVersion(b)), "%s == %s", a, b)
+			}
+		}
+	}
+}
+
+func TestParseMavenVersionRange(t *testing.T) {
+	tests := []struct {
+		spec     string
+		matches  []string
+		excludes []string
+	}{
+		{
+			spec:     "1.0",
+			matches:  []string{"0.1", "1.0", "5.0"},
+			excludes: []string{},
+		},
+		{
+			spec:     "[1.2]",
+			matches:  []string{"1.2", "1.2.0"},
+			excludes: []string{"1.2.1", "1.1"},
+		},
+		{
+			spec:     "[1.0,2.0)",
+			matches:  []string{"1.0", "1.5", "2.0-SNAPSHOT"},
+			excludes: []string{"0.9", "2.0", "2.1"},
+		},
+		{
+			spec:     "(,1.5]",
+			matches:  []string{"1.0", "1.5"},
+			excludes: []string{"1.5.1", "1.5-sp1"},
+		},
+		{
+			spec:     "(1.0,)",
+			matches:  []string{"1.0.1", "1.0-sp1"},
+			excludes: []string{"1.0", "1.0-rc1"},
+		},
+		{
+			spec:     "(,1.0],[1.2,)",
+			matches:  []string{"0.5", "1.0", "1.2", "3.0"},
+			excludes: []string{"1.1", "1.2-rc1"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.spec, func(t *testing.T) {
+			c, err := ParseMavenVersionRange(test.spec)
+			require.NoError(t, err)
+			assert.Equal(t, test.spec, c.String())
+
+			for _, v := range test.matches {
+				assert.True(t, c.Matches(MustMavenVersion(v)), "should match %s", v)
+			}
+			for _, v := range test.excludes {
+				assert.False(t, c.Matches(MustMavenVersion(v)), "should not match %s", v)
+			}
+		})
+	}
+}
+
+func TestParseMavenVersionRange_invalid(t *testing.T) {
+	tests := map[string]string{
+		"":            `invalid maven version range: empty`,
+		"[1.0,2.0":    `invalid maven version range "[1.0,2.0": missing closing bracket`,
+		"(1.0)":       `invalid maven version range "(1.0)": single version range "(1.0)" must be inclusive`,
+		"[,1.0]":      `invalid maven version range "[,1.0]": unbounded range "[,1.0]" must be exclusive`,
+		"[2.0,1.0]":   `invalid maven version range "[2.0,1.0]": range "[2.0,1.0]" lower bound is greater than upper bound`,
+		"[1,2,3]":     `invalid maven version range "[1,2,3]": range "[1,2,3]" must have one or two bounds`,
+		"[1.0][2.0]":  `invalid maven version range "[1.0][2.0]": expected ',' between ranges`,
+		"[1.0],":      `invalid maven version range "[1.0],": expected ',' between ranges`,
+		"[1.0],x2.0]": `invalid maven version range "[1.0],x2.0]": range "x2.0]" must start with '[' or '('`,
+	}
+	for spec, expectedErr := range tests {
+		t.Run(spec, func(t *testing.T) {
+			_, err := ParseMavenVersionRange(spec)
+			require.EqualError(t, err, expectedErr)
+		})
+	}
+}
+
+func FuzzNewMavenVersion(f *testing.F) {
+	fuzzVersionRoundTrip(f, ParseMavenVersion,
+		"1.0-SNAPSHOT", "1-alpha-1", "1.0.0000000000000000000000000001", "1..1", "1-.a")
+}
+
+func FuzzParseMavenVersionRange(f *testing.F) {
+	fuzzConstraintRoundTrip(f, ParseMavenVersionRange,
+		"1.0", "[1.0]", "[1.0,2.0)", "(,1.0],[1.2,)", "[1.5,)")
+}
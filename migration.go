@@ -0,0 +1,146 @@
+package main
+
+// Declares that a project migrated from one version scheme to another.
+// All versions of the previous scheme sort directly before the Before version,
+// e.g. sequence versions 1-57 precede semver 1.0.0.
+type SchemeMigration struct {
+	// Name of the previous version scheme.
+	From string
+	// First version of the new scheme.
+	Before Version
+}
+
+// Orders versions of all schemes a project has used.
+// Migrations may be chained, e.g. sequence -> calver -> semver.
+type SchemeMigrations []SchemeMigration
+
+// Compare returns -1 if a < b, 0 if a == b and 1 if a > b.
+// Versions of the same scheme or schemes without migration
+// are compared using Version.Equal and Version.Less.
+// The order of the migrations does not matter.
+func (m SchemeMigrations) Compare(a, b Version) int {
+	return m.chain().compare(a, b)
+}
+
+// Schemes of chained migrations, ordered from the oldest to the current scheme.
+type schemeChain struct {
+	// position of each scheme in the chain
+	positions map[string]int
+	// migration away from each scheme
+	next map[string]SchemeMigration
+}
+
+// Orders the migrations by following From -> Before.Scheme(),
+// starting at schemes no migration leads to. Cycles end the chain.
+func (m SchemeMigrations) chain() schemeChain {
+	c := schemeChain{positions: map[string]int{}, next: map[string]SchemeMigration{}}
+	targets := map[string]struct{}{}
+	for _, migration := range m {
+		if _, ok := c.next[migration.From]; !ok {
+			c.next[migration.From] = migration
+		}
+		targets[migration.Before.Scheme()] = struct{}{}
+	}
+	for _, migration := range m {
+		if _, ok := targets[migration.From]; ok {
+			continue
+		}
+		scheme := migration.From
+		for pos := 0; ; pos++ {
+			if _, ok := c.positions[scheme]; ok {
+				break
+			}
+			c.positions[scheme] = pos
+			next, ok := c.next[scheme]
+			if !ok {
+				break
+			}
+			scheme = next.Before.Scheme()
+		}
+	}
+	return c
+}
+
+// Versions of an older scheme sort directly before the first version of the next scheme.
+// Recursion only moves towards newer schemes, so it ends at the scheme of b.
+func (c schemeChain) compare(a, b Version) int {
+	posA, okA := c.positions[a.Scheme()]
+	posB, okB := c.positions[b.Scheme()]
+	switch {
+	case a.Scheme() == b.Scheme() || !okA || !okB || posA == posB:
+		return compareVersions(a, b)
+	case posA > posB:
+		return -c.compare(b, a)
+	}
+	next, ok := c.next[a.Scheme()]
+	if !ok || c.positions[next.Before.Scheme()] <= posA {
+		// branched migrations, that are not a single chain.
+		return compareVersions(a, b)
+	}
+	if c.compare(next.Before, b) <= 0 {
+		return -1
+	}
+	return 1
+}
+
+// Schemes returns the names of all schemes that are part of the migrations.
+func (m SchemeMigrations) Schemes() []string {
+	var schemes []string
+	for _, migration := range m {
+		schemes = append(schemes, migration.From, migration.Before.Scheme())
+	}
+	return schemes
+}
+
+// Constraint returns the given constraint,
+// with all operator constraints comparing versions across schemes using the migrations.
+// Dialect constraints only match versions of their own scheme.
+func (m SchemeMigrations) Constraint(c VersionConstraint) VersionConstraint {
+	if len(m) == 0 {
+		return c
+	}
+	switch c := c.(type) {
+	case Constraint:
+		return migratedConstraint{Constraint: c, migrations: m}
+	case ConstraintAND:
+		and := make(ConstraintAND, len(c))
+		for i := range c {
+			and[i] = m.Constraint(c[i])
+		}
+		return and
+	case ConstraintOR:
+		or := make(ConstraintOR, len(c))
+		for i := range c {
+			or[i] = m.Constraint(c[i])
+		}
+		return or
+	default:
+		return c
+	}
+}
+
+type migratedConstraint struct {
+	Constraint
+	migrations SchemeMigrations
+}
+
+func (c migratedConstraint) Matches(v Version) bool {
+	if c.version.Scheme() == v.Scheme() {
+		return c.Constraint.Matches(v)
+	}
+	return c.operator.compares(c.migrations.Compare(v, c.version))
+}
+
+// Sorts ProjectVersions descending, ordering versions of different schemes by the migrations.
+type ProjectVersionsMigratedDescending struct {
+	Versions   []ProjectVersion
+	Migrations SchemeMigrations
+}
+
+func (a ProjectVersionsMigratedDescending) Len() int { return len(a.Versions) }
+func (a ProjectVersionsMigratedDescending) Swap(i, j int) {
+	a.Versions[i], a.Versions[j] = a.Versions[j], a.Versions[i]
+}
+func (a ProjectVersionsMigratedDescending) Less(i, j int) bool {
+	return a.Migrations.Compare(a.Versions[i].Version, a.Versions[j].Version) > 0
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemeMigrations_Compare(t *testing.T) {
+	calver := func(v string) Version {
+		return MustCalendarVersion(DefaultCalendarVersionFormat, v)
+	}
+	migrations := SchemeMigrations{
+		{From: "sequence", Before: calver("2020.01.01")},
+		{From: "calver", Before: MustSemanticVersion("1.0.0")},
+	}
+
+	tests := []struct {
+		name     string
+		a, b     Version
+		expected int
+	}{
+		{name: "sequence before calver", a: MustSequenceVersion("57"), b: calver("2020.01.01"), expected: -1},
+		{name: "calver after sequence", a: calver("2021.06.01"), b: MustSequenceVersion("57"), expected: 1},
+		{name: "calver before semver", a: calver("2022.12.31"), b: MustSemanticVersion("1.0.0"), expected: -1},
+		{name: "semver after calver", a: MustSemanticVersion("2.0.0"), b: calver("2022.12.31"), expected: 1},
+		{name: "chained", a: MustSequenceVersion("57"), b: MustSemanticVersion("1.0.0"), expected: -1},
+		{name: "chained reverse", a: MustSemanticVersion("1.0.0"), b: MustSequenceVersion("1"), expected: 1},
+		{name: "same scheme", a: MustSemanticVersion("1.0.0"), b: MustSemanticVersion("1.0.0"), expected: 0},
+		{name: "chained over calver", a: MustSequenceVersion("57"), b: calver("2021.01.01"), expected: -1},
+	}
+	// declaration order does not matter.
+	reversed := SchemeMigrations{migrations[1], migrations[0]}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, migrations.Compare(test.a, test.b))
+			assert.Equal(t, test.expected, reversed.Compare(test.a, test.b))
+			assert.Equal(t, -test.expected, reversed.Compare(test.b, test.a))
+		})
+	}
+}
+
+func TestSchemeMigrations_Compare_cycle(t *testing.T) {
+	migrations := SchemeMigrations{
+		{From: "sequence", Before: MustSemanticVersion("1.0.0")},
+		{From: "semver", Before: MustSequenceVersion("1")},
+	}
+	// no chain, versions of different schemes are not ordered by the migrations.
+	assert.Equal(t, compareVersions(MustSequenceVersion("57"), MustSemanticVersion("1.0.0")),
+		migrations.Compare(MustSequenceVersion("57"), MustSemanticVersion("1.0.0")))
+}
+
+func TestSchemeMigrations_Constraint(t *testing.T) {
+	migrations := SchemeMigrations{
+		{From: "sequence", Before: MustSemanticVersion("1.0.0")},
+	}
+
+	c := migrations.Constraint(ConstraintAND{
+		*NewConstraint(Less, MustSemanticVersion("1.0.0")),
+	})
+	assert.True(t, c.Matches(MustSequenceVersion("57")))
+	assert.Equal(t, "<1.0.0", c.String())
+
+	c = migrations.Constraint(ConstraintOR{
+		*NewConstraint(GreaterOrEqual, MustSemanticVersion("1.0.0")),
+		*NewConstraint(Equal, MustSequenceVersion("12")),
+	})
+	assert.False(t, c.Matches(MustSequenceVersion("57")))
+	assert.True(t, c.Matches(MustSequenceVersion("12")))
+}
+
+func TestInMemoryDB_Add_migrations(t *testing.T) {
+	calver := func(v string) Version {
+		return MustCalendarVersion(DefaultCalendarVersionFormat, v)
+	}
+
+	ctx := context.Background()
+	db := NewInMemoryDB()
+	require.NoError(t, db.Add(ctx, Project{
+		Name:   "A",
+		Scheme: "semver",
+		Migrations: SchemeMigrations{
+			{From: "calver", Before: MustSemanticVersion("1.0.0")},
+		},
+		Versions: []ProjectVersion{
+			{Version: calver("2021.03.01")},
+			{Version: MustSemanticVersion("1.1.0")},
+			{Version: calver("2022.11.15")},
+			{Version: MustSemanticVersion("1.0.0")},
+		},
+	}))
+
+	project, err := db.Get(ctx, "A")
+	require.NoError(t, err)
+	var versions []string
+	for _, pv := range project.Versions {
+		versions = append(versions, pv.Version.String())
+	}
+	assert.Equal(t, []string{"1.1.0", "1.0.0", "2022.11.15", "2021.03.01"}, versions)
+}
+
+func TestResolver_migrations(t *testing.T) {
+	project, err := DefaultVersionSchemes.UnmarshalProject([]byte(`{
+		"name": "A",
+		"scheme": "semver",
+		"migrations": [{"from": "calver", "before": "1.0.0"}],
+		"versions": [
+			{"version": "1.0.0"},
+			{"version": "2022.11.15", "scheme": "calver"},
+			{"version": "2021.03.01", "scheme": "calver"}
+		]
+	}`))
+	require.NoError(t, err)
+	require.NoError(t, DefaultVersionSchemes.ValidateProject(project))
+
+	data, err := DefaultVersionSchemes.MarshalProject(project)
+	require.NoError(t, err)
+	roundTripped, err := DefaultVersionSchemes.UnmarshalProject(data)
+	require.NoError(t, err)
+	assert.Equal(t, project, roundTripped)
+
+	ctx := context.Background()
+	db := NewInMemoryDB()
+	require.NoError(t, db.Add(ctx, project))
+
+	r := NewResolver(db)
+	result, err := r.Resolve(ctx, []Dependency{
+		{Name: "A", Constraints: []VersionConstraint{
+			*NewConstraint(Less, MustSemanticVersion("1.0.0")),
+		}},
+	})
+	require.NoError(t, err)
+
+	sort.Sort(ResolverProjectVersionByName(result))
+	assert.Equal(t, []ResolverProjectVersion{
+		{Name: "A", Version: "2022.11.15"},
+	}, result)
+}
@@ -0,0 +1,490 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// npm range syntax, as implemented by node-semver.
+// https://github.com/npm/node-semver#ranges
+//
+// Ranges are desugared into sets of primitive comparators:
+//
+//	^1.2.3         >=1.2.3 <2.0.0-0
+//	~1.2           >=1.2.0 <1.3.0-0
+//	1.x            >=1.0.0 <2.0.0-0
+//	1.2.3 - 2.3    >=1.2.3 <2.4.0-0
+//	>1.2           >=1.3.0
+//	*              >=0.0.0
+//
+// A version with a prerelease tag only matches a comparator set,
+// if one comparator in the set has a prerelease tag on the same [major, minor, patch] tuple.
+type npmRange struct {
+	raw  string
+	sets []npmComparatorSet
+}
+
+var (
+	_ VersionConstraint = npmRange{}
+	_ VersionConstraint = npmComparatorSet{}
+	_ VersionConstraint = npmComparator{}
+
+	_ ExactConstraint   = npmRange{}
+	_ ExactConstraint   = npmComparatorSet{}
+	_ ExactConstraint   = npmComparator{}
+	_ DialectConstraint = npmRange{}
+)
+
+var (
+	npmPartialRegexp = regexp.MustCompile(
+		`^v?(x|X|\*|0|[1-9]\d*)(?:\.(x|X|\*|0|[1-9]\d*)(?:\.(x|X|\*|0|[1-9]\d*)` +
+			`(?:-(` + npmPrereleaseIdentifier + `(?:\.` + npmPrereleaseIdentifier + `)*))?` +
+			`(?:\+[0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*)?)?)?$`)
+	npmHyphenRegexp       = regexp.MustCompile(`^(\S+)\s+-\s+(\S+)$`)
+	npmOperatorTrimRegexp = regexp.MustCompile(`(<=|>=|<|>|=|~>|~|\^)\s+`)
+)
+
+// Numeric pre-release identifiers must not have leading zeros.
+const npmPrereleaseIdentifier = `(?:0|[1-9]\d*|\d*[A-Za-z-][0-9A-Za-z-]*)`
+
+// Operators in the order they are matched.
+var npmOperators = []string{"~>", "~", "^", ">=", "<=", ">", "<", "="}
+
+// Name of the npm range dialect of the semver scheme.
+const NPMDialect = "npm"
+
+// ParseNPMRange parses an npm range into a constraint tree over SemanticVersion.
+func ParseNPMRange(r string) (VersionConstraint, error) {
+	nr := npmRange{raw: strings.TrimSpace(r)}
+	for _, s := range strings.Split(r, "||") {
+		set, err := parseNPMComparatorSet(strings.TrimSpace(s))
+		if err != nil {
+			return nil, fmt.Errorf("invalid npm range %q: %w", r, err)
+		}
+		nr.sets = append(nr.sets, set)
+	}
+	return nr, nil
+}
+
+func (r npmRange) Matches(v Version) bool {
+	for _, set := range r.sets {
+		if set.Matches(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r npmRange) String() string {
+	return r.raw
+}
+
+func (r npmRange) Versions() []Version {
+	var versions []Version
+	for _, set := range r.sets {
+		versions = append(versions, set.Versions()...)
+	}
+	return versions
+}
+
+func (r npmRange) Dialect() string {
+	return NPMDialect
+}
+
+func (r npmRange) ExactVersion() (Version, bool) {
+	if len(r.sets) != 1 {
+		return nil, false
+	}
+	return r.sets[0].ExactVersion()
+}
+
+// Comparators that all need to match.
+type npmComparatorSet []npmComparator
+
+func parseNPMComparatorSet(s string) (npmComparatorSet, error) {
+	if len(s) == 0 {
+		return npmComparatorSet{npmAny()}, nil
+	}
+
+	if m := npmHyphenRegexp.FindStringSubmatch(s); m != nil {
+		from, err := parseNPMPartial(m[1])
+		if err != nil {
+			return nil, err
+		}
+		to, err := parseNPMPartial(m[2])
+		if err != nil {
+			return nil, err
+		}
+		return npmHyphenRange(from, to)
+	}
+
+	var set npmComparatorSet
+	for _, token := range strings.Fields(npmOperatorTrimRegexp.ReplaceAllString(s, "$1")) {
+		var op string
+		for _, o := range npmOperators {
+			if strings.HasPrefix(token, o) {
+				op = o
+				break
+			}
+		}
+
+		p, err := parseNPMPartial(token[len(op):])
+		if err != nil {
+			return nil, err
+		}
+
+		var comparators []npmComparator
+		switch op {
+		case "~", "~>":
+			comparators, err = npmTildeRange(p)
+		case "^":
+			comparators, err = npmCaretRange(p)
+		default:
+			comparators, err = npmPrimitiveRange(op, p)
+		}
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, comparators...)
+	}
+	return set, nil
+}
+
+// Matches without include prerelease option, see testSet in node-semver.
+func (set npmComparatorSet) Matches(v Version) bool {
+	sv, ok := v.(*SemanticVersion)
+	if !ok || sv == nil {
+		return false
+	}
+
+	for _, c := range set {
+		if !c.Matches(sv) {
+			return false
+		}
+	}
+
+	if len(sv.Prerelease()) == 0 {
+		return true
+	}
+	for _, c := range set {
+		allowed := c.version.Version
+		if len(allowed.Prerelease()) != 0 &&
+			allowed.Major() == sv.Major() &&
+			allowed.Minor() == sv.Minor() &&
+			allowed.Patch() == sv.Patch() {
+			return true
+		}
+	}
+	return false
+}
+
+func (set npmComparatorSet) String() string {
+	s := make([]string, len(set))
+	for i, c := range set {
+		s[i] = c.String()
+	}
+	return strings.Join(s, " ")
+}
+
+func (set npmComparatorSet) Versions() []Version {
+	versions := make([]Version, len(set))
+	for i, c := range set {
+		versions[i] = c.version
+	}
+	return versions
+}
+
+func (set npmComparatorSet) ExactVersion() (Version, bool) {
+	if len(set) != 1 {
+		return nil, false
+	}
+	return set[0].ExactVersion()
+}
+
+// Primitive comparator, without npm's prerelease rules.
+type npmComparator struct {
+	operator string
+	version  *SemanticVersion
+}
+
+func (c npmComparator) Matches(v Version) bool {
+	sv, ok := v.(*SemanticVersion)
+	if !ok || sv == nil {
+		return false
+	}
+
+	cmp := sv.Version.Compare(c.version.Version)
+	switch c.operator {
+	case "=":
+		return cmp == 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	default:
+		return false
+	}
+}
+
+func (c npmComparator) String() string {
+	return c.operator + c.version.String()
+}
+
+func (c npmComparator) Versions() []Version {
+	return []Version{c.version}
+}
+
+func (c npmComparator) ExactVersion() (Version, bool) {
+	return c.version, c.operator == "="
+}
+
+// Partial version, wildcard components are -1.
+type npmPartial struct {
+	major, minor, patch int
+	prerelease          string
+}
+
+func parseNPMPartial(s string) (npmPartial, error) {
+	m := npmPartialRegexp.FindStringSubmatch(s)
+	if m == nil {
+		return npmPartial{}, fmt.Errorf("invalid version %q", s)
+	}
+
+	p := npmPartial{prerelease: m[4]}
+	for i, dst := range []*int{&p.major, &p.minor, &p.patch} {
+		switch m[i+1] {
+		case "", "x", "X", "*":
+			*dst = -1
+		default:
+			n, err := strconv.Atoi(m[i+1])
+			if err != nil {
+				return npmPartial{}, fmt.Errorf("invalid version %q: %w", s, err)
+			}
+			*dst = n
+		}
+	}
+	// everything after a wildcard is a wildcard too: 1.x.3 == 1.x.x
+	if p.major == -1 {
+		p.minor = -1
+	}
+	if p.minor == -1 {
+		p.patch = -1
+	}
+	if p.patch == -1 {
+		p.prerelease = ""
+	}
+	return p, nil
+}
+
+func (p npmPartial) anyX() bool {
+	return p.major == -1 || p.minor == -1 || p.patch == -1
+}
+
+func npmAny() npmComparator {
+	return npmComparator{operator: ">=", version: &SemanticVersion{Version: semver.New(0, 0, 0, "", "")}}
+}
+
+func npmCmp(op string, major, minor, patch int, prerelease string) (npmComparator, error) {
+	v, err := semver.NewVersion(fmt.Sprintf("%d.%d.%d", major, minor, patch))
+	if err != nil {
+		return npmComparator{}, fmt.Errorf("invalid version %d.%d.%d: %w", major, minor, patch, err)
+	}
+	if len(prerelease) != 0 {
+		withPrerelease, err := v.SetPrerelease(prerelease)
+		if err != nil {
+			return npmComparator{}, fmt.Errorf("invalid prerelease %q: %w", prerelease, err)
+		}
+		v = &withPrerelease
+	}
+	return npmComparator{operator: op, version: &SemanticVersion{Version: v}}, nil
+}
+
+// Builds a comparator for the version following the last non-wildcard component,
+// e.g. 1.x => 2.0.0, 1.2.x => 1.3.0, 1.2.3 => 1.2.4.
+func npmCmpNext(op string, major, minor, patch int, prerelease string) (npmComparator, error) {
+	switch {
+	case minor == -1:
+		if major == math.MaxInt {
+			return npmComparator{}, fmt.Errorf("major version %d is too large", major)
+		}
+		return npmCmp(op, major+1, 0, 0, prerelease)
+	case patch == -1:
+		if minor == math.MaxInt {
+			return npmComparator{}, fmt.Errorf("minor version %d is too large", minor)
+		}
+		return npmCmp(op, major, minor+1, 0, prerelease)
+	default:
+		if patch == math.MaxInt {
+			return npmComparator{}, fmt.Errorf("patch version %d is too large", patch)
+		}
+		return npmCmp(op, major, minor, patch+1, prerelease)
+	}
+}
+
+// Builds a range from a lower and an upper bound.
+func npmBounds(
+	lower func() (npmComparator, error),
+	upper func() (npmComparator, error),
+) ([]npmComparator, error) {
+	l, err := lower()
+	if err != nil {
+		return nil, err
+	}
+	u, err := upper()
+	if err != nil {
+		return nil, err
+	}
+	return []npmComparator{l, u}, nil
+}
+
+// Wraps a single comparator.
+func npmSingle(c npmComparator, err error) ([]npmComparator, error) {
+	if err != nil {
+		return nil, err
+	}
+	return []npmComparator{c}, nil
+}
+
+// Desugars x-ranges and partial versions in primitive comparators,
+// see replaceXRange in node-semver.
+func npmPrimitiveRange(op string, p npmPartial) ([]npmComparator, error) {
+	if len(op) == 0 {
+		op = "="
+	}
+	if !p.anyX() {
+		return npmSingle(npmCmp(op, p.major, p.minor, p.patch, p.prerelease))
+	}
+
+	switch {
+	case p.major == -1:
+		if op == ">" || op == "<" {
+			// nothing is allowed
+			return npmSingle(npmCmp("<", 0, 0, 0, "0"))
+		}
+		return []npmComparator{npmAny()}, nil
+
+	case op == "=":
+		return npmXRange(p)
+
+	case op == ">":
+		// >1 => >=2.0.0, >1.2 => >=1.3.0
+		return npmSingle(npmCmpNext(">=", p.major, p.minor, -1, ""))
+
+	case op == "<=":
+		// <=0.7.x is actually <0.8.0-0
+		return npmSingle(npmCmpNext("<", p.major, p.minor, -1, "0"))
+
+	case op == "<":
+		return npmSingle(npmCmp("<", p.major, zeroX(p.minor), 0, "0"))
+
+	default:
+		return npmSingle(npmCmp(op, p.major, zeroX(p.minor), 0, ""))
+	}
+}
+
+// 1.x => >=1.0.0 <2.0.0-0, 1.2.x => >=1.2.0 <1.3.0-0
+func npmXRange(p npmPartial) ([]npmComparator, error) {
+	if p.major == -1 {
+		return []npmComparator{npmAny()}, nil
+	}
+	return npmBounds(
+		func() (npmComparator, error) { return npmCmp(">=", p.major, zeroX(p.minor), 0, "") },
+		func() (npmComparator, error) { return npmCmpNext("<", p.major, p.minor, -1, "0") },
+	)
+}
+
+// ~1.2.3 => >=1.2.3 <1.3.0-0, ~1.2 => >=1.2.0 <1.3.0-0, ~1 => >=1.0.0 <2.0.0-0
+func npmTildeRange(p npmPartial) ([]npmComparator, error) {
+	if p.anyX() {
+		return npmXRange(p)
+	}
+	return npmBounds(
+		func() (npmComparator, error) { return npmCmp(">=", p.major, p.minor, p.patch, p.prerelease) },
+		func() (npmComparator, error) { return npmCmpNext("<", p.major, p.minor, -1, "0") },
+	)
+}
+
+// ^1.2.3 => >=1.2.3 <2.0.0-0, ^0.2.3 => >=0.2.3 <0.3.0-0, ^0.0.3 => >=0.0.3 <0.0.4-0
+func npmCaretRange(p npmPartial) ([]npmComparator, error) {
+	switch {
+	case p.major == -1:
+		return []npmComparator{npmAny()}, nil
+	case p.minor == -1:
+		return npmXRange(p)
+	case p.patch == -1:
+		if p.major == 0 {
+			return npmXRange(p)
+		}
+		return npmBounds(
+			func() (npmComparator, error) { return npmCmp(">=", p.major, p.minor, 0, "") },
+			func() (npmComparator, error) { return npmCmpNext("<", p.major, -1, -1, "0") },
+		)
+	}
+
+	lower := func() (npmComparator, error) { return npmCmp(">=", p.major, p.minor, p.patch, p.prerelease) }
+	switch {
+	case p.major == 0 && p.minor == 0:
+		return npmBounds(lower, func() (npmComparator, error) { return npmCmpNext("<", 0, 0, p.patch, "0") })
+	case p.major == 0:
+		return npmBounds(lower, func() (npmComparator, error) { return npmCmpNext("<", 0, p.minor, -1, "0") })
+	default:
+		return npmBounds(lower, func() (npmComparator, error) { return npmCmpNext("<", p.major, -1, -1, "0") })
+	}
+}
+
+// 1.2.3 - 2.3.4 => >=1.2.3 <=2.3.4, 1.2 - 2.3 => >=1.2.0 <2.4.0-0
+func npmHyphenRange(from, to npmPartial) (npmComparatorSet, error) {
+	var set npmComparatorSet
+	if from.major != -1 {
+		var (
+			lower npmComparator
+			err   error
+		)
+		if from.anyX() {
+			lower, err = npmCmp(">=", from.major, zeroX(from.minor), 0, "")
+		} else {
+			lower, err = npmCmp(">=", from.major, from.minor, from.patch, from.prerelease)
+		}
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, lower)
+	}
+
+	if to.major != -1 {
+		var (
+			upper npmComparator
+			err   error
+		)
+		if to.anyX() {
+			upper, err = npmCmpNext("<", to.major, to.minor, -1, "0")
+		} else {
+			upper, err = npmCmp("<=", to.major, to.minor, to.patch, to.prerelease)
+		}
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, upper)
+	}
+
+	if len(set) == 0 {
+		set = append(set, npmAny())
+	}
+	return set, nil
+}
+
+func zeroX(n int) int {
+	if n == -1 {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Ported from node-semver test/fixtures/range-include.js,
+// without loose and includePrerelease cases.
+var npmRangeInclude = [][2]string{
+	{"1.0.0 - 2.0.0", "1.2.3"},
+	{"^1.2.3+build", "1.2.3"},
+	{"^1.2.3+build", "1.3.0"},
+	{"1.2.3-pre+asdf - 2.4.3-pre+asdf", "1.2.3"},
+	{"1.2.3-pre+asdf - 2.4.3-pre+asdf", "1.2.3-pre.2"},
+	{"1.2.3-pre+asdf - 2.4.3-pre+asdf", "2.4.3-alpha"},
+	{"1.2.3+asdf - 2.4.3+asdf", "1.2.3"},
+	{"1.0.0", "1.0.0"},
+	{">=*", "0.2.4"},
+	{"", "1.0.0"},
+	{"*", "1.2.3"},
+	{">=1.0.0", "1.0.0"},
+	{">=1.0.0", "1.0.1"},
+	{">=1.0.0", "1.1.0"},
+	{">1.0.0", "1.0.1"},
+	{">1.0.0", "1.1.0"},
+	{"<=2.0.0", "2.0.0"},
+	{"<=2.0.0", "1.9999.9999"},
+	{"<=2.0.0", "0.2.9"},
+	{"<2.0.0", "1.9999.9999"},
+	{"<2.0.0", "0.2.9"},
+	{">= 1.0.0", "1.0.0"},
+	{">=  1.0.0", "1.0.1"},
+	{">=   1.0.0", "1.1.0"},
+	{"> 1.0.0", "1.0.1"},
+	{">  1.0.0", "1.1.0"},
+	{"<=   2.0.0", "2.0.0"},
+	{"<= 2.0.0", "1.9999.9999"},
+	{"<=  2.0.0", "0.2.9"},
+	{"<    2.0.0", "1.9999.9999"},
+	{"<\t2.0.0", "0.2.9"},
+	{">=0.1.97", "0.1.97"},
+	{"0.1.20 || 1.2.4", "1.2.4"},
+	{">=0.2.3 || <0.0.1", "0.0.0"},
+	{">=0.2.3 || <0.0.1", "0.2.3"},
+	{">=0.2.3 || <0.0.1", "0.2.4"},
+	{"||", "1.3.4"},
+	{"2.x.x", "2.1.3"},
+	{"1.2.x", "1.2.3"},
+	{"1.2.x || 2.x", "2.1.3"},
+	{"1.2.x || 2.x", "1.2.3"},
+	{"x", "1.2.3"},
+	{"2.*.*", "2.1.3"},
+	{"1.2.*", "1.2.3"},
+	{"1.2.* || 2.*", "2.1.3"},
+	{"1.2.* || 2.*", "1.2.3"},
+	{"2", "2.1.2"},
+	{"2.3", "2.3.1"},
+	{"~0.0.1", "0.0.1"},
+	{"~0.0.1", "0.0.2"},
+	{"~x", "0.0.9"},
+	{"~2", "2.0.9"},
+	{"~2.4", "2.4.0"},
+	{"~2.4", "2.4.5"},
+	{"~>3.2.1", "3.2.2"},
+	{"~1", "1.2.3"},
+	{"~>1", "1.2.3"},
+	{"~> 1", "1.2.3"},
+	{"~1.0", "1.0.2"},
+	{"~ 1.0", "1.0.2"},
+	{"~ 1.0.3", "1.0.12"},
+	{">=1", "1.0.0"},
+	{">= 1", "1.0.0"},
+	{"<1.2", "1.1.1"},
+	{"< 1.2", "1.1.1"},
+	{"~v0.5.4-pre", "0.5.5"},
+	{"~v0.5.4-pre", "0.5.4"},
+	{"=0.7.x", "0.7.2"},
+	{"<=0.7.x", "0.7.2"},
+	{">=0.7.x", "0.7.2"},
+	{"<=0.7.x", "0.6.2"},
+	{"~1.2.1 >=1.2.3", "1.2.3"},
+	{"~1.2.1 =1.2.3", "1.2.3"},
+	{"~1.2.1 1.2.3", "1.2.3"},
+	{"~1.2.1 >=1.2.3 1.2.3", "1.2.3"},
+	{"~1.2.1 1.2.3 >=1.2.3", "1.2.3"},
+	{">=1.2.1 1.2.3", "1.2.3"},
+	{"1.2.3 >=1.2.1", "1.2.3"},
+	{">=1.2.3 >=1.2.1", "1.2.3"},
+	{">=1.2.1 >=1.2.3", "1.2.3"},
+	{">=1.2", "1.2.8"},
+	{"^1.2.3", "1.8.1"},
+	{"^0.1.2", "0.1.2"},
+	{"^0.1", "0.1.2"},
+	{"^0.0.1", "0.0.1"},
+	{"^1.2", "1.4.2"},
+	{"^1.2 ^1", "1.4.2"},
+	{"^1.2.3-alpha", "1.2.3-pre"},
+	{"^1.2.0-alpha", "1.2.0-pre"},
+	{"^0.0.1-alpha", "0.0.1-beta"},
+	{"^0.0.1-alpha", "0.0.1"},
+	{"^0.1.1-alpha", "0.1.1-beta"},
+	{"^x", "1.2.3"},
+	{"x - 1.0.0", "0.9.7"},
+	{"x - 1.x", "0.9.7"},
+	{"1.0.0 - x", "1.9.7"},
+	{"1.x - x", "1.9.7"},
+	{"<=7.x", "7.9.9"},
+}
+
+// Ported from node-semver test/fixtures/range-exclude.js,
+// without loose and includePrerelease cases.
+var npmRangeExclude = [][2]string{
+	{"1.0.0 - 2.0.0", "2.2.3"},
+	{"1.2.3+asdf - 2.4.3+asdf", "1.2.3-pre.2"},
+	{"1.2.3+asdf - 2.4.3+asdf", "2.4.3-alpha"},
+	{"^1.2.3+build", "2.0.0"},
+	{"^1.2.3+build", "1.2.0"},
+	{"^1.2.3", "1.2.3-pre"},
+	{"^1.2", "1.2.0-pre"},
+	{">1.2", "1.3.0-beta"},
+	{"<=1.2.3", "1.2.3-beta"},
+	{"^1.2.3", "1.2.3-beta"},
+	{"=0.7.x", "0.7.0-asdf"},
+	{">=0.7.x", "0.7.0-asdf"},
+	{"<=0.7.x", "0.7.0-asdf"},
+	{"1.0.0", "1.0.1"},
+	{">=1.0.0", "0.0.0"},
+	{">=1.0.0", "0.0.1"},
+	{">=1.0.0", "0.1.0"},
+	{">1.0.0", "0.0.1"},
+	{">1.0.0", "0.1.0"},
+	{"<=2.0.0", "3.0.0"},
+	{"<=2.0.0", "2.9999.9999"},
+	{"<=2.0.0", "2.2.9"},
+	{"<2.0.0", "2.9999.9999"},
+	{"<2.0.0", "2.2.9"},
+	{">=0.1.97", "0.1.93"},
+	{"0.1.20 || 1.2.4", "1.2.3"},
+	{">=0.2.3 || <0.0.1", "0.0.3"},
+	{">=0.2.3 || <0.0.1", "0.2.2"},
+	{"2.x.x", "3.1.3"},
+	{"1.2.x", "1.3.3"},
+	{"1.2.x || 2.x", "3.1.3"},
+	{"1.2.x || 2.x", "1.1.3"},
+	{"2.*.*", "1.1.3"},
+	{"2.*.*", "3.1.3"},
+	{"1.2.*", "1.3.3"},
+	{"1.2.* || 2.*", "3.1.3"},
+	{"1.2.* || 2.*", "1.1.3"},
+	{"2", "1.1.2"},
+	{"2.3", "2.4.1"},
+	{"~0.0.1", "0.1.0-alpha"},
+	{"~0.0.1", "0.1.0"},
+	{"~2.4", "2.5.0"},
+	{"~2.4", "2.3.9"},
+	{"~>3.2.1", "3.3.2"},
+	{"~>3.2.1", "3.2.0"},
+	{"~1", "0.2.3"},
+	{"~>1", "2.2.3"},
+	{"~1.0", "1.1.0"},
+	{"<1", "1.0.0"},
+	{">=1.2", "1.1.1"},
+	{"~v0.5.4-beta", "0.5.4-alpha"},
+	{"=0.7.x", "0.8.2"},
+	{">=0.7.x", "0.6.2"},
+	{"<0.7.x", "0.7.2"},
+	{"<1.2.3", "1.2.3-beta"},
+	{"=1.2.3", "1.2.3-beta"},
+	{">1.2", "1.2.8"},
+	{"^0.0.1", "0.0.2-alpha"},
+	{"^0.0.1", "0.0.2"},
+	{"^1.2.3", "2.0.0-alpha"},
+	{"^1.2.3", "1.2.2"},
+	{"^1.2", "1.1.9"},
+	{"^1.0.0", "2.0.0-rc1"},
+	{"1 - 2", "2.0.0-pre"},
+	{"1 - 2", "1.0.0-pre"},
+	{"1.0 - 2", "1.0.0-pre"},
+	{"1.1.x", "1.0.0-a"},
+	{"1.1.x", "1.1.0-a"},
+	{"1.1.x", "1.2.0-a"},
+	{"1.x", "1.0.0-a"},
+	{"1.x", "1.1.0-a"},
+	{"1.x", "1.2.0-a"},
+	{">=1.0.0 <1.1.0", "1.1.0"},
+	{">=1.0.0 <1.1.0", "1.1.0-pre"},
+	{">=1.0.0 <1.1.0-pre", "1.1.0-pre"},
+}
+
+func TestParseNPMRange(t *testing.T) {
+	for _, test := range npmRangeInclude {
+		c, err := ParseNPMRange(test[0])
+		require.NoError(t, err, test[0])
+		assert.True(t, c.Matches(MustSemanticVersion(test[1])), "%q should include %s", test[0], test[1])
+	}
+	for _, test := range npmRangeExclude {
+		c, err := ParseNPMRange(test[0])
+		require.NoError(t, err, test[0])
+		assert.False(t, c.Matches(MustSemanticVersion(test[1])), "%q should exclude %s", test[0], test[1])
+	}
+}
+
+func TestParseNPMRange_desugar(t *testing.T) {
+	tests := map[string]string{
+		"^1.2.3":        ">=1.2.3 <2.0.0-0",
+		"^0.2.3":        ">=0.2.3 <0.3.0-0",
+		"^0.0.3":        ">=0.0.3 <0.0.4-0",
+		"^1.2.x":        ">=1.2.0 <2.0.0-0",
+		"^0.0.x":        ">=0.0.0 <0.1.0-0",
+		"^0.x":          ">=0.0.0 <1.0.0-0",
+		"~1.2.3-beta.2": ">=1.2.3-beta.2 <1.3.0-0",
+		"1.2 - 2.3":     ">=1.2.0 <2.4.0-0",
+		"1.2.3 - 2":     ">=1.2.3 <3.0.0-0",
+		">1":            ">=2.0.0",
+		">1.2":          ">=1.3.0",
+		"<=1.2":         "<1.3.0-0",
+		">*":            "<0.0.0-0",
+		"*":             ">=0.0.0",
+	}
+	for r, expected := range tests {
+		t.Run(r, func(t *testing.T) {
+			c, err := ParseNPMRange(r)
+			require.NoError(t, err)
+			assert.Equal(t, r, c.String())
+			assert.Equal(t, expected, c.(npmRange).sets[0].String())
+		})
+	}
+}
+
+func TestParseNPMRange_invalid(t *testing.T) {
+	_, err := ParseNPMRange(">=1.2.3 || ~foo")
+	require.EqualError(t, err, `invalid npm range ">=1.2.3 || ~foo": invalid version "foo"`)
+
+	_, err = ParseNPMRange("1.2-beta")
+	require.EqualError(t, err, `invalid npm range "1.2-beta": invalid version "1.2-beta"`)
+
+	_, err = ParseNPMRange(">=1.2.3-01")
+	require.EqualError(t, err, `invalid npm range ">=1.2.3-01": invalid version "1.2.3-01"`)
+
+	overflows := map[string]string{
+		"^9223372036854775807":        "major version 9223372036854775807 is too large",
+		"9223372036854775807.x":       "major version 9223372036854775807 is too large",
+		">9223372036854775807":        "major version 9223372036854775807 is too large",
+		"1.2.3 - 9223372036854775807": "major version 9223372036854775807 is too large",
+		"~1.9223372036854775807":      "minor version 9223372036854775807 is too large",
+		"^0.0.9223372036854775807":    "patch version 9223372036854775807 is too large",
+	}
+	for r, expectedErr := range overflows {
+		_, err := ParseNPMRange(r)
+		require.EqualError(t, err, fmt.Sprintf("invalid npm range %q: %s", r, expectedErr))
+	}
+}
+
+func FuzzParseNPMRange(f *testing.F) {
+	fuzzConstraintRoundTrip(f, ParseNPMRange,
+		"^1.2.3", "~1.2", "1.x || >=2.5.0", "1.2.3 - 2.3.4", ">=1.2.3-rc.1 <2", "*",
+		"^9223372036854775807", "9223372036854775807.x", ">9223372036854775807",
+		"1.2.3 - 9223372036854775807", "~1.9223372036854775807", "^0.0.9223372036854775807")
+}
@@ -0,0 +1,87 @@
+package main
+
+import "fmt"
+
+// Root overrides for broken upstream metadata,
+// applied to all dependencies of the tree.
+type Overrides struct {
+	// Constraints to ignore.
+	Ignore []IgnoredConstraint
+	// Constraints replacing all other constraints on a project, e.g. C=2.0.1.
+	Force []Dependency
+	// Versions that are never selected.
+	Exclude []ResolverProjectVersion
+}
+
+// Ignores all constraints of one project on another project.
+type IgnoredConstraint struct {
+	// Name of the project declaring the constraints.
+	Origin string
+	// Name of the project the constraints target.
+	Subject string
+}
+
+// Describes how an override changed the resolution.
+type OverrideKind int
+
+const (
+	// A constraint was ignored.
+	OverrideIgnore OverrideKind = iota
+	// A constraint was replaced by forced constraints.
+	OverrideForce
+	// A version was excluded.
+	OverrideExclude
+)
+
+// Records an override that was applied to the solution.
+type ResolverOverride struct {
+	Kind OverrideKind
+	// Constraint that was ignored or replaced, for OverrideIgnore and OverrideForce.
+	// A force on a selected project is also recorded with only the SubjectProjectName set.
+	Constraint ResolverConstraint
+	// Constraints replacing the Constraint, for OverrideForce.
+	Forced []VersionConstraint
+	// Version that was excluded, for OverrideExclude.
+	Excluded ResolverProjectVersion
+}
+
+func (ro ResolverOverride) String() string {
+	switch ro.Kind {
+	case OverrideIgnore:
+		return fmt.Sprintf("ignored %s", ro.Constraint)
+	case OverrideForce:
+		if len(ro.Constraint.Constraints) == 0 {
+			return fmt.Sprintf("forced %q to %s", ro.Constraint.SubjectProjectName, ConstraintAND(ro.Forced))
+		}
+		return fmt.Sprintf(
+			"forced %q to %s, replacing %s",
+			ro.Constraint.SubjectProjectName, ConstraintAND(ro.Forced), ro.Constraint)
+	default:
+		return fmt.Sprintf("excluded %s", ro.Excluded)
+	}
+}
+
+// Returns the override ignoring or replacing the given constraint, if any.
+func (o Overrides) constraintOverride(c ResolverConstraint) (ResolverOverride, bool) {
+	for _, ignore := range o.Ignore {
+		if ignore.Origin == c.Origin.Name && ignore.Subject == c.SubjectProjectName {
+			return ResolverOverride{Kind: OverrideIgnore, Constraint: c}, true
+		}
+	}
+	for _, force := range o.Force {
+		if force.Name == c.SubjectProjectName {
+			return ResolverOverride{Kind: OverrideForce, Constraint: c, Forced: force.Constraints}, true
+		}
+	}
+	return ResolverOverride{}, false
+}
+
+// Returns true, if the given project version is excluded.
+func (o Overrides) excludes(rpv ResolverProjectVersion) bool {
+	for _, excluded := range o.Exclude {
+		if excluded == rpv {
+			return true
+		}
+	}
+	return false
+}
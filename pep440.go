@@ -0,0 +1,458 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Python package version as specified by PEP 440.
+// https://peps.python.org/pep-0440/
+type PEP440Version struct {
+	epoch   int
+	release []int
+	// pre-release phase "a", "b" or "rc", empty if not a pre-release.
+	prePhase string
+	pre      int
+	// -1 if not a post-release.
+	post int
+	// -1 if not a development release.
+	dev   int
+	local []string
+}
+
+var (
+	_ Version           = (*PEP440Version)(nil)
+	_ PrereleaseVersion = (*PEP440Version)(nil)
+)
+
+var PEP440VersionScheme = VersionScheme{
+	Name:  "pep440",
+	Parse: ParsePEP440Version,
+	ParseConstraint: func(constraint string) (VersionConstraint, error) {
+		return ParsePEP440Specifier(constraint)
+	},
+}
+
+// Regular expression from PEP 440 Appendix B.
+var pep440VersionRegexp = regexp.MustCompile(`(?i)^v?` +
+	`(?:(?P<epoch>[0-9]+)!)?` +
+	`(?P<release>[0-9]+(?:\.[0-9]+)*)` +
+	`(?P<pre>[-_\.]?(?P<pre_l>alpha|a|beta|b|preview|pre|c|rc)[-_\.]?(?P<pre_n>[0-9]+)?)?` +
+	`(?P<post>(?:-(?P<post_n1>[0-9]+))|(?:[-_\.]?(?P<post_l>post|rev|r)[-_\.]?(?P<post_n2>[0-9]+)?))?` +
+	`(?P<dev>[-_\.]?(?P<dev_l>dev)[-_\.]?(?P<dev_n>[0-9]+)?)?` +
+	`(?:\+(?P<local>[a-z0-9]+(?:[-_\.][a-z0-9]+)*))?$`)
+
+func MustPEP440Version(v string) *PEP440Version {
+	pv, err := NewPEP440Version(v)
+	if err != nil {
+		panic(err)
+	}
+	return pv
+}
+
+func NewPEP440Version(v string) (*PEP440Version, error) {
+	m := pep440VersionRegexp.FindStringSubmatch(strings.TrimSpace(v))
+	if m == nil {
+		return nil, fmt.Errorf("invalid PEP 440 version %q", v)
+	}
+	group := func(name string) string {
+		return m[pep440VersionRegexp.SubexpIndex(name)]
+	}
+	// keeps the first error of number.
+	var err error
+	number := func(s string) int {
+		if len(s) == 0 || err != nil {
+			return 0
+		}
+		// only digits, as ensured by the regexp, that may still overflow
+		n, nerr := strconv.Atoi(s)
+		if nerr != nil {
+			err = fmt.Errorf("invalid PEP 440 version %q: %w", v, nerr)
+		}
+		return n
+	}
+
+	pv := &PEP440Version{
+		epoch: number(group("epoch")),
+		post:  -1,
+		dev:   -1,
+	}
+	for _, s := range strings.Split(group("release"), ".") {
+		pv.release = append(pv.release, number(s))
+	}
+
+	switch strings.ToLower(group("pre_l")) {
+	case "":
+	case "a", "alpha":
+		pv.prePhase = "a"
+	case "b", "beta":
+		pv.prePhase = "b"
+	default:
+		pv.prePhase = "rc"
+	}
+	pv.pre = number(group("pre_n"))
+
+	if len(group("post")) != 0 {
+		pv.post = number(group("post_n1") + group("post_n2"))
+	}
+	if len(group("dev")) != 0 {
+		pv.dev = number(group("dev_n"))
+	}
+	if local := group("local"); len(local) != 0 {
+		pv.local = strings.FieldsFunc(strings.ToLower(local), func(r rune) bool {
+			return r == '.' || r == '-' || r == '_'
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pv, nil
+}
+
+func ParsePEP440Version(v string) (Version, error) {
+	return NewPEP440Version(v)
+}
+
+func (pv *PEP440Version) Equal(v Version) bool {
+	otherPV, ok := v.(*PEP440Version)
+	if !ok || otherPV == nil {
+		return false
+	}
+	return pv.compare(otherPV) == 0
+}
+
+func (pv *PEP440Version) Less(v Version) bool {
+	otherPV, ok := v.(*PEP440Version)
+	if !ok || otherPV == nil {
+		return false
+	}
+	return pv.compare(otherPV) < 0
+}
+
+// String returns the normalized form of the version.
+func (pv *PEP440Version) String() string {
+	var b strings.Builder
+	if pv.epoch != 0 {
+		fmt.Fprintf(&b, "%d!", pv.epoch)
+	}
+	b.WriteString(joinInts(pv.release, "."))
+	if len(pv.prePhase) != 0 {
+		fmt.Fprintf(&b, "%s%d", pv.prePhase, pv.pre)
+	}
+	if pv.post != -1 {
+		fmt.Fprintf(&b, ".post%d", pv.post)
+	}
+	if pv.dev != -1 {
+		fmt.Fprintf(&b, ".dev%d", pv.dev)
+	}
+	if len(pv.local) != 0 {
+		b.WriteString("+" + strings.Join(pv.local, "."))
+	}
+	return b.String()
+}
+
+func (pv *PEP440Version) Scheme() string {
+	return PEP440VersionScheme.Name
+}
+
+// IsPrerelease returns true for pre- and development releases.
+func (pv *PEP440Version) IsPrerelease() bool {
+	return len(pv.prePhase) != 0 || pv.dev != -1
+}
+
+// Public returns the version without local version label.
+func (pv *PEP440Version) Public() *PEP440Version {
+	public := *pv
+	public.local = nil
+	return &public
+}
+
+// Comparison following the ordering rules of PEP 440.
+func (pv *PEP440Version) compare(other *PEP440Version) int {
+	if c := compareInt(pv.epoch, other.epoch); c != 0 {
+		return c
+	}
+	if c := compareReleases(pv.release, other.release); c != 0 {
+		return c
+	}
+
+	// pre-releases sort before the final release,
+	// dev releases of the final release sort before its pre-releases.
+	if c := compareInt(pv.preRank(), other.preRank()); c != 0 {
+		return c
+	}
+	if c := compareInt(pv.pre, other.pre); c != 0 {
+		return c
+	}
+
+	// post-releases sort after the release, -1 sorts before any post-release.
+	if c := compareInt(pv.post, other.post); c != 0 {
+		return c
+	}
+
+	// dev releases sort before the release they belong to.
+	if c := compareInt(pv.devRank(), other.devRank()); c != 0 {
+		return c
+	}
+	return compareLocals(pv.local, other.local)
+}
+
+func (pv *PEP440Version) preRank() int {
+	switch {
+	case len(pv.prePhase) == 0 && pv.post == -1 && pv.dev != -1:
+		return 0
+	case pv.prePhase == "a":
+		return 1
+	case pv.prePhase == "b":
+		return 2
+	case pv.prePhase == "rc":
+		return 3
+	default:
+		return 4
+	}
+}
+
+func (pv *PEP440Version) devRank() int {
+	if pv.dev == -1 {
+		return int(^uint(0) >> 1)
+	}
+	return pv.dev
+}
+
+// Returns true if both versions have the same epoch and release segment.
+func (pv *PEP440Version) sameRelease(other *PEP440Version) bool {
+	return pv.epoch == other.epoch && compareReleases(pv.release, other.release) == 0
+}
+
+// Returns true when the release segment of pv starts with the given prefix.
+func (pv *PEP440Version) hasReleasePrefix(epoch int, prefix []int) bool {
+	if pv.epoch != epoch {
+		return false
+	}
+	for i, n := range prefix {
+		var r int
+		if i < len(pv.release) {
+			r = pv.release[i]
+		}
+		if r != n {
+			return false
+		}
+	}
+	return true
+}
+
+// Compares release segments, padding the shorter one with zeros.
+func compareReleases(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var ai, bi int
+		if i < len(a) {
+			ai = a[i]
+		}
+		if i < len(b) {
+			bi = b[i]
+		}
+		if c := compareInt(ai, bi); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// Versions without local label sort before versions with one.
+// Numeric segments sort after alphanumeric ones.
+func compareLocals(a, b []string) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		an, aErr := strconv.Atoi(a[i])
+		bn, bErr := strconv.Atoi(b[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if c := compareInt(an, bn); c != 0 {
+				return c
+			}
+		case aErr == nil:
+			return 1
+		case bErr == nil:
+			return -1
+		default:
+			if c := strings.Compare(a[i], b[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return compareInt(len(a), len(b))
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func joinInts(ints []int, sep string) string {
+	s := make([]string, len(ints))
+	for i, n := range ints {
+		s[i] = strconv.Itoa(n)
+	}
+	return strings.Join(s, sep)
+}
+
+// PEP 440 version specifier clause, e.g. "~=1.2" or "==1.*".
+type pep440Specifier struct {
+	operator string
+	version  *PEP440Version
+	// unparsed version for arbitrary equality "==="
+	raw string
+	// "==1.*" style prefix match
+	wildcard bool
+}
+
+var (
+	_ VersionConstraint = pep440Specifier{}
+	_ ExactConstraint   = pep440Specifier{}
+)
+
+// Ordered so longer operators are matched first.
+var pep440SpecifierOperators = []string{"===", "~=", "==", "!=", "<=", ">=", "<", ">"}
+
+// ParsePEP440Specifier parses a comma separated list of PEP 440 specifier clauses,
+// e.g. ">=1.0, !=1.3.*, <2.0", into a ConstraintAND.
+func ParsePEP440Specifier(specifier string) (ConstraintAND, error) {
+	var and ConstraintAND
+	for _, clause := range strings.Split(specifier, ",") {
+		s, err := parsePEP440SpecifierClause(strings.TrimSpace(clause))
+		if err != nil {
+			return nil, fmt.Errorf("invalid PEP 440 specifier %q: %w", specifier, err)
+		}
+		and = append(and, s)
+	}
+	return and, nil
+}
+
+func parsePEP440SpecifierClause(clause string) (pep440Specifier, error) {
+	var op string
+	for _, o := range pep440SpecifierOperators {
+		if strings.HasPrefix(clause, o) {
+			op = o
+			break
+		}
+	}
+	if len(op) == 0 {
+		return pep440Specifier{}, fmt.Errorf("unknown op in %q", clause)
+	}
+
+	s := pep440Specifier{operator: op}
+	v := strings.TrimSpace(clause[len(op):])
+	if op == "===" {
+		if len(v) == 0 {
+			return pep440Specifier{}, fmt.Errorf("missing version in %q", clause)
+		}
+		s.raw = v
+		return s, nil
+	}
+
+	if strings.HasSuffix(v, ".*") {
+		if op != "==" && op != "!=" {
+			return pep440Specifier{}, fmt.Errorf("wildcard not allowed with %s", op)
+		}
+		s.wildcard = true
+		v = strings.TrimSuffix(v, ".*")
+	}
+
+	pv, err := NewPEP440Version(v)
+	if err != nil {
+		return pep440Specifier{}, err
+	}
+	s.version = pv
+
+	switch {
+	case s.wildcard && (pv.IsPrerelease() || pv.post != -1 || len(pv.local) != 0):
+		return pep440Specifier{}, fmt.Errorf("wildcard only allowed on release segment in %q", clause)
+	case len(pv.local) != 0 && op != "==" && op != "!=":
+		return pep440Specifier{}, fmt.Errorf("local version not allowed with %s", op)
+	case op == "~=" && len(pv.release) < 2:
+		return pep440Specifier{}, fmt.Errorf("%s needs at least two release segments", op)
+	}
+	return s, nil
+}
+
+func (s pep440Specifier) Matches(v Version) bool {
+	if s.operator == "===" {
+		return strings.EqualFold(s.raw, v.String())
+	}
+
+	pv, ok := v.(*PEP440Version)
+	if !ok || pv == nil {
+		return false
+	}
+
+	switch s.operator {
+	case "==":
+		return s.equal(pv)
+	case "!=":
+		return !s.equal(pv)
+	case "~=":
+		prefix := s.version.release[:len(s.version.release)-1]
+		return pv.Public().compare(s.version) >= 0 &&
+			pv.hasReleasePrefix(s.version.epoch, prefix)
+	case "<=":
+		return pv.Public().compare(s.version) <= 0
+	case ">=":
+		return pv.Public().compare(s.version) >= 0
+	case "<":
+		// <V must not match pre-releases of V, unless V is a pre-release itself.
+		return pv.compare(s.version) < 0 &&
+			!(!s.version.IsPrerelease() && pv.IsPrerelease() && pv.sameRelease(s.version))
+	case ">":
+		// >V must not match post-releases or local versions of V.
+		return pv.compare(s.version) > 0 &&
+			!(s.version.post == -1 && pv.post != -1 && pv.sameRelease(s.version)) &&
+			!(len(pv.local) != 0 && pv.Public().compare(s.version) == 0)
+	default:
+		return false
+	}
+}
+
+func (s pep440Specifier) equal(pv *PEP440Version) bool {
+	switch {
+	case s.wildcard:
+		return pv.hasReleasePrefix(s.version.epoch, s.version.release)
+	case len(s.version.local) == 0:
+		// local labels are ignored, if the specifier has none.
+		return pv.Public().compare(s.version) == 0
+	default:
+		return pv.compare(s.version) == 0
+	}
+}
+
+func (s pep440Specifier) String() string {
+	switch {
+	case s.operator == "===":
+		return s.operator + s.raw
+	case s.wildcard:
+		return s.operator + s.version.String() + ".*"
+	default:
+		return s.operator + s.version.String()
+	}
+}
+
+func (s pep440Specifier) Versions() []Version {
+	if s.version == nil {
+		return nil
+	}
+	return []Version{s.version}
+}
+
+// Only "==" without wildcard is exact, "===" compares strings and has no parsed version.
+func (s pep440Specifier) ExactVersion() (Version, bool) {
+	if s.operator != "==" || s.wildcard {
+		return nil, false
+	}
+	return s.version, true
+}
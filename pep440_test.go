@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPEP440Version(t *testing.T) {
+	pv, err := NewPEP440Version("1.0")
+	require.NoError(t, err)
+
+	assert.False(t, pv.Equal(nil))
+	assert.True(t, pv.Equal(pv))
+	assert.True(t, pv.Equal(MustPEP440Version("1.0.0")))
+	assert.False(t, pv.Equal(MustPEP440Version("1.0.1")))
+
+	assert.False(t, pv.Less(nil))
+	assert.False(t, pv.Less(MustPEP440Version("1.0rc1")))
+	assert.True(t, pv.Less(MustPEP440Version("1.0.post1")))
+
+	_, err = NewPEP440Version("1.0-foo")
+	require.EqualError(t, err, `invalid PEP 440 version "1.0-foo"`)
+
+	assert.Panics(t, func() {
+		MustPEP440Version("xxx")
+	})
+}
+
+func TestPEP440Version_normalization(t *testing.T) {
+	tests := map[string]string{
+		"v1.0":             "1.0",
+		"1.0-ALPHA.1":      "1.0a1",
+		"1.0.beta":         "1.0b0",
+		"1.0c2":            "1.0rc2",
+		"1.0preview3":      "1.0rc3",
+		"1.0-1":            "1.0.post1",
+		"1.0.rev":          "1.0.post0",
+		"1.0-dev_2":        "1.0.dev2",
+		"1!2.0+Ubuntu-1.3": "1!2.0+ubuntu.1.3",
+	}
+	for in, expected := range tests {
+		t.Run(in, func(t *testing.T) {
+			assert.Equal(t, expected, MustPEP440Version(in).String())
+		})
+	}
+}
+
+func TestPEP440Version_ordering(t *testing.T) {
+	// Ascending order, as listed in PEP 440 "Summary of permitted suffixes and relative ordering".
+	ordered := []string{
+		"1.dev0",
+		"1.0.dev456",
+		"1.0a1",
+		"1.0a2.dev456",
+		"1.0a12.dev456",
+		"1.0a12",
+		"1.0b1.dev456",
+		"1.0b2",
+		"1.0b2.post345.dev456",
+		"1.0b2.post345",
+		"1.0rc1.dev456",
+		"1.0rc1",
+		"1.0",
+		"1.0+abc.5",
+		"1.0+abc.7",
+		"1.0+5",
+		"1.0.post456.dev34",
+		"1.0.post456",
+		"1.0.15",
+		"1.1.dev1",
+		"1!0.1",
+	}
+	for i := range ordered {
+		for j := range ordered {
+			a, b := MustPEP440Version(ordered[i]), MustPEP440Version(ordered[j])
+			assert.Equal(t, i < j, a.Less(b), "%s < %s", a, b)
+			assert.Equal(t, i == j, a.Equal(b), "%s == %s", a, b)
+		}
+	}
+}
+
+func TestParsePEP440Specifier(t *testing.T) {
+	tests := []struct {
+		specifier string
+		matches   []string
+		excludes  []string
+	}{
+		{
+			specifier: "~=2.2",
+			matches:   []string{"2.2", "2.3", "2.9.post1"},
+			excludes:  []string{"2.1", "3.0"},
+		},
+		{
+			specifier: "~=1.4.5",
+			matches:   []string{"1.4.5", "1.4.9"},
+			excludes:  []string{"1.5.0", "1.4.4"},
+		},
+		{
+			specifier: "==1.1.*",
+			matches:   []string{"1.1", "1.1.0", "1.1.7", "1.1.post1"},
+			excludes:  []string{"1.10", "1.2"},
+		},
+		{
+			specifier: "==1.1",
+			matches:   []string{"1.1", "1.1.0", "1.1+local"},
+			excludes:  []string{"1.1.1", "1.1.post1"},
+		},
+		{
+			specifier: "==1.1+local",
+			matches:   []string{"1.1+local"},
+			excludes:  []string{"1.1", "1.1+other"},
+		},
+		{
+			specifier: "!=1.3.*",
+			matches:   []string{"1.2", "1.4"},
+			excludes:  []string{"1.3", "1.3.2"},
+		},
+		{
+			specifier: "<2.0",
+			matches:   []string{"1.9", "1.9.post1"},
+			excludes:  []string{"2.0", "2.0rc1", "2.0.dev1"},
+		},
+		{
+			specifier: ">1.7",
+			matches:   []string{"1.7.1", "1.8"},
+			excludes:  []string{"1.7", "1.7.post2", "1.7+local"},
+		},
+		{
+			specifier: ">=1.0, <2.0, !=1.5",
+			matches:   []string{"1.0", "1.4", "1.9"},
+			excludes:  []string{"0.9", "1.5", "2.0"},
+		},
+		{
+			specifier: "===foobar",
+			matches:   []string{},
+			excludes:  []string{"1.0"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.specifier, func(t *testing.T) {
+			c, err := ParsePEP440Specifier(test.specifier)
+			require.NoError(t, err)
+			assert.Equal(t, test.specifier, c.String())
+
+			for _, v := range test.matches {
+				assert.True(t, c.Matches(MustPEP440Version(v)), "should match %s", v)
+			}
+			for _, v := range test.excludes {
+				assert.False(t, c.Matches(MustPEP440Version(v)), "should not match %s", v)
+			}
+		})
+	}
+}
+
+func TestParsePEP440Specifier_invalid(t *testing.T) {
+	tests := map[string]string{
+		"1.0":      `invalid PEP 440 specifier "1.0": unknown op in "1.0"`,
+		"~=1":      `invalid PEP 440 specifier "~=1": ~= needs at least two release segments`,
+		">=1.*":    `invalid PEP 440 specifier ">=1.*": wildcard not allowed with >=`,
+		"==1.0a.*": `invalid PEP 440 specifier "==1.0a.*": wildcard only allowed on release segment in "==1.0a.*"`,
+		">1.0+abc": `invalid PEP 440 specifier ">1.0+abc": local version not allowed with >`,
+		"==99999999999999999999": `invalid PEP 440 specifier "==99999999999999999999": ` +
+			`invalid PEP 440 version "99999999999999999999": ` +
+			`strconv.Atoi: parsing "99999999999999999999": value out of range`,
+	}
+	for specifier, expectedErr := range tests {
+		t.Run(specifier, func(t *testing.T) {
+			_, err := ParsePEP440Specifier(specifier)
+			require.EqualError(t, err, expectedErr)
+		})
+	}
+}
+
+func TestResolver_pep440(t *testing.T) {
+	ctx := context.Background()
+	db := NewInMemoryDB()
+
+	for _, data := range []string{
+		`{"name": "requests", "scheme": "pep440", "versions": [
+			{"version": "2.31.0", "dependencies": [{"name": "urllib3", "constraints": [">=1.21.1, <3"]}]},
+			{"version": "2.25.0", "dependencies": [{"name": "urllib3", "constraints": [">=1.21.1, <1.27"]}]}
+		]}`,
+		`{"name": "botocore", "scheme": "pep440", "versions": [
+			{"version": "1.29.0", "dependencies": [{"name": "urllib3", "constraints": ["~=1.25.4"]}]}
+		]}`,
+		`{"name": "urllib3", "scheme": "pep440", "versions": [
+			{"version": "2.0.4"}, {"version": "1.26.16"}, {"version": "1.25.11"}
+		]}`,
+	} {
+		p, err := DefaultVersionSchemes.UnmarshalProject([]byte(data))
+		require.NoError(t, err)
+		require.NoError(t, db.Add(ctx, p))
+	}
+
+	r := NewResolver(db)
+	result, err := r.Resolve(ctx, []Dependency{
+		{Name: "requests"}, {Name: "botocore"},
+	})
+	require.NoError(t, err)
+
+	sort.Sort(ResolverProjectVersionByName(result))
+	assert.Equal(t, []ResolverProjectVersion{
+		{Name: "botocore", Version: "1.29.0"},
+		{Name: "requests", Version: "2.31.0"},
+		{Name: "urllib3", Version: "1.25.11"},
+	}, result)
+}
+
+func FuzzNewPEP440Version(f *testing.F) {
+	fuzzVersionRoundTrip(f, ParsePEP440Version,
+		"1!2.0.0rc1.post2.dev3+local.7", "1.0a1", "v1.0-RC.1", "1.0.post", "1.0-1", "1.0_dev_2", "99999999999999999999")
+}
+
+func FuzzParsePEP440Specifier(f *testing.F) {
+	fuzzConstraintRoundTrip(f, func(s string) (VersionConstraint, error) {
+		return ParsePEP440Specifier(s)
+	}, "~=1.4.5", ">=1.0, !=1.3.*, <2", "===foo", "==1.0.*", "<1.0a1")
+}
@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 
@@ -14,24 +16,200 @@ import (
 type Resolver struct {
 	db ProjectDB
 
+	prereleasePolicy   PrereleasePolicy
+	prereleaseProjects map[string]struct{}
+	providerPriorities map[string][]string
+	dependencyKinds    map[DependencyKind]struct{}
+	environments       []Environment
+	uniquenessKeys     map[string]UniquenessKey
+	lockedVersions     map[ResolverProjectVersion]struct{}
+	overrides          Overrides
+
 	resolveOnce               sync.Once
 	resolved                  []ResolverProjectVersion
 	gini                      *gini.Gini
 	projects                  []Project
+	discovered                map[string]struct{}
+	discoveredProjects        map[string]struct{}
+	capabilityProviders       map[string][]string
+	discoveryOrder            []resolverDecision
+	decisions                 []resolverDecision
+	substitutions             []ResolverSubstitution
+	links                     []ResolverLink
+	warnings                  []ResolverWarning
+	overridden                []ResolverOverride
+	appliedOverrides          []ResolverOverride
 	projectConstraints        map[string][]ResolverConstraint
+	projectConflicts          map[string][]ResolverConstraint
+	projectDependencies       map[ResolverProjectVersion][]Dependency
+	projectsToLiterals        map[string][]z.Lit
+	capabilitiesToLiterals    map[string][]z.Lit
 	projectVersionsToLiterals map[ResolverProjectVersion]z.Lit
+	featuresToLiterals        map[ResolverProjectVersion]z.Lit
+	featureRequests           []resolverFeatureRequest
+	enabledFeatures           map[ResolverProjectVersion][]string
+	installedEnvironments     map[string][]Environment
+}
+
+// Controls which pre-release versions are considered by the resolver.
+type PrereleasePolicy int
+
+const (
+	// Pre-releases are only considered,
+	// if a constraint referencing a pre-release version explicitly requests them.
+	PrereleasesIfRequested PrereleasePolicy = iota
+	// Pre-releases compete equally with stable versions.
+	PrereleasesAllowed
+)
+
+// Configures optional resolver behavior.
+type ResolverOption func(r *Resolver)
+
+// WithPrereleasePolicy sets the pre-release policy for all projects.
+// Defaults to PrereleasesIfRequested.
+func WithPrereleasePolicy(policy PrereleasePolicy) ResolverOption {
+	return func(r *Resolver) {
+		r.prereleasePolicy = policy
+	}
+}
+
+// WithPrereleasesFor allows pre-releases of the given projects.
+func WithPrereleasesFor(projectNames ...string) ResolverOption {
+	return func(r *Resolver) {
+		for _, name := range projectNames {
+			r.prereleaseProjects[name] = struct{}{}
+		}
+	}
+}
+
+// WithProviderPriority prefers the given providers of a capability in order.
+// Providers that are not listed are tried afterwards, ordered by name.
+func WithProviderPriority(capabilityName string, projectNames ...string) ResolverOption {
+	return func(r *Resolver) {
+		r.providerPriorities[capabilityName] = projectNames
+	}
+}
+
+// WithDependencyKinds only resolves dependencies of the given kinds,
+// e.g. DependencyRuntime and DependencyBuild for a production build.
+// Defaults to all kinds.
+// Test and dev dependencies are only resolved for the root dependencies.
+// Peer dependencies never add projects and are always considered.
+func WithDependencyKinds(kinds ...DependencyKind) ResolverOption {
+	return func(r *Resolver) {
+		r.dependencyKinds = map[DependencyKind]struct{}{}
+		for _, kind := range kinds {
+			r.dependencyKinds[kind] = struct{}{}
+		}
+	}
+}
+
+// WithEnvironment resolves for the given target environment.
+// Dependencies with a Marker not matching the environment are skipped.
+// Without an environment, markers are ignored.
+func WithEnvironment(env Environment) ResolverOption {
+	return WithEnvironments(env)
+}
+
+// WithEnvironments resolves a single solution that is valid for all given target environments,
+// e.g. for a lockfile shared by linux and darwin hosts.
+// Projects are only installed in the environments that need them,
+// but use the same version in every environment.
+func WithEnvironments(envs ...Environment) ResolverOption {
+	return func(r *Resolver) {
+		r.environments = envs
+	}
+}
+
+// WithUniquenessKey allows versions of the given projects to coexist,
+// if they have different keys, e.g. using MajorVersionKey.
+// Each dependent links to the latest installed version matching its constraints.
+func WithUniquenessKey(key UniquenessKey, projectNames ...string) ResolverOption {
+	return func(r *Resolver) {
+		for _, name := range projectNames {
+			r.uniquenessKeys[name] = key
+		}
+	}
+}
+
+// WithLockedVersions declares the versions of an existing lockfile.
+// Locked versions stay selectable, even if they have been yanked.
+func WithLockedVersions(versions ...ResolverProjectVersion) ResolverOption {
+	return func(r *Resolver) {
+		for _, rpv := range versions {
+			r.lockedVersions[rpv] = struct{}{}
+		}
+	}
+}
+
+// WithOverrides applies the given overrides to all dependencies of the tree.
+func WithOverrides(overrides Overrides) ResolverOption {
+	return func(r *Resolver) {
+		r.overrides = overrides
+	}
+}
+
+// A decision of the greedy pass,
+// with candidates to try in order of preference.
+// Each candidate is a set of literals that are assumed together.
+type resolverDecision struct {
+	name       string
+	capability bool
+	candidates [][]z.Lit
+}
+
+// Records features requested by a dependency.
+type resolverFeatureRequest struct {
+	origin     ResolverProjectVersion
+	dependency Dependency
+}
+
+// Records that a project depended on by name was replaced by another project.
+type ResolverSubstitution struct {
+	Replaced string
+	By       ResolverProjectVersion
+}
+
+func (rs ResolverSubstitution) String() string {
+	return fmt.Sprintf("%s replaces %q", rs.By, rs.Replaced)
+}
+
+// Warns about a selected project version, e.g. because it is deprecated.
+type ResolverWarning struct {
+	ProjectVersion ResolverProjectVersion
+	Message        string
+}
+
+func (rw ResolverWarning) String() string {
+	return fmt.Sprintf("%s: %s", rw.ProjectVersion, rw.Message)
+}
+
+// Records which project version a dependent uses to satisfy a dependency.
+type ResolverLink struct {
+	From ResolverProjectVersion
+	To   ResolverProjectVersion
+}
+
+func (rl ResolverLink) String() string {
+	return fmt.Sprintf("%s -> %s", rl.From, rl.To)
 }
 
 type ResolverProjectVersion struct {
 	Name    string
 	Version string
+	// Feature of the project version, if any.
+	Feature string
 }
 
 func (rpv ResolverProjectVersion) String() string {
-	if len(rpv.Version) == 0 {
-		return rpv.Name
+	s := rpv.Name
+	if len(rpv.Version) != 0 {
+		s += "=" + rpv.Version
 	}
-	return rpv.Name + "=" + rpv.Version
+	if len(rpv.Feature) != 0 {
+		s += "[" + rpv.Feature + "]"
+	}
+	return s
 }
 
 // Records constraints for the resolver and their source.
@@ -40,7 +218,12 @@ type ResolverConstraint struct {
 	Origin ResolverProjectVersion
 	// ProjectName the constrain targets.
 	SubjectProjectName string
-	Constraints        []Constraint
+	Constraints        []VersionConstraint
+	// Conflict is true, if the Origin cannot be installed
+	// with versions matching the constraints.
+	Conflict bool
+	// Marker limits the constraint to matching target environments, if not nil.
+	Marker Marker
 }
 
 func (rc ResolverConstraint) String() string {
@@ -49,19 +232,49 @@ func (rc ResolverConstraint) String() string {
 		constraints = append(constraints, c.String())
 	}
 
+	if rc.Conflict {
+		if len(constraints) == 0 {
+			return fmt.Sprintf("%s conflicts with %q", rc.Origin, rc.SubjectProjectName)
+		}
+		return fmt.Sprintf(
+			"%s conflicts with %q matching %s",
+			rc.Origin, rc.SubjectProjectName, strings.Join(constraints, ", "))
+	}
 	return fmt.Sprintf(
 		"%s constrains %q with %s",
 		rc.Origin, rc.SubjectProjectName, strings.Join(constraints, ", "))
 }
 
-func NewResolver(db ProjectDB) *Resolver {
-	return &Resolver{
+// Project and Version that is the source of dependencies passed to Resolve.
+var rootProjectVersion = ResolverProjectVersion{Name: "root"}
+
+func NewResolver(db ProjectDB, opts ...ResolverOption) *Resolver {
+	r := &Resolver{
 		db: db,
 
+		prereleaseProjects: map[string]struct{}{},
+		providerPriorities: map[string][]string{},
+		uniquenessKeys:     map[string]UniquenessKey{},
+		lockedVersions:     map[ResolverProjectVersion]struct{}{},
+
 		gini:                      gini.New(),
+		discovered:                map[string]struct{}{},
+		discoveredProjects:        map[string]struct{}{},
+		capabilityProviders:       map[string][]string{},
 		projectConstraints:        map[string][]ResolverConstraint{},
+		projectConflicts:          map[string][]ResolverConstraint{},
+		projectDependencies:       map[ResolverProjectVersion][]Dependency{},
+		projectsToLiterals:        map[string][]z.Lit{},
+		capabilitiesToLiterals:    map[string][]z.Lit{},
 		projectVersionsToLiterals: map[ResolverProjectVersion]z.Lit{},
+		featuresToLiterals:        map[ResolverProjectVersion]z.Lit{},
+		enabledFeatures:           map[ResolverProjectVersion][]string{},
+		installedEnvironments:     map[string][]Environment{},
+	}
+	for _, opt := range opts {
+		opt(r)
 	}
+	return r
 }
 
 func (r *Resolver) Resolve(ctx context.Context, rootDeps []Dependency) ([]ResolverProjectVersion, error) {
@@ -80,32 +293,195 @@ func (r *Resolver) ConstrainsFor(ctx context.Context, projectName string) []Reso
 	return r.projectConstraints[projectName]
 }
 
+// FeaturesFor returns the features enabled for the given selected project version.
+// Versions installed side by side have their own features.
+func (r *Resolver) FeaturesFor(ctx context.Context, rpv ResolverProjectVersion) []string {
+	return r.enabledFeatures[rpv]
+}
+
+// Substitutions returns the replaced projects of the solution and their replacements.
+func (r *Resolver) Substitutions(ctx context.Context) []ResolverSubstitution {
+	return r.substitutions
+}
+
+// EnvironmentsFor returns the target environments the given project is installed in.
+// Returns nil, if the resolver has no target environments.
+func (r *Resolver) EnvironmentsFor(ctx context.Context, projectName string) []Environment {
+	return r.installedEnvironments[projectName]
+}
+
+// Links returns the project version each dependent of the solution uses,
+// ordered by dependent.
+func (r *Resolver) Links(ctx context.Context) []ResolverLink {
+	return r.links
+}
+
+// Warnings returns warnings about selected project versions, ordered by project.
+func (r *Resolver) Warnings(ctx context.Context) []ResolverWarning {
+	return r.warnings
+}
+
+// AppliedOverrides returns the overrides that changed the solution:
+// ignored and replaced constraints of selected versions and all excluded versions.
+func (r *Resolver) AppliedOverrides(ctx context.Context) []ResolverOverride {
+	return r.appliedOverrides
+}
+
+// ConflictsFor returns all conflicts declared against the given project.
+func (r *Resolver) ConflictsFor(ctx context.Context, projectName string) []ResolverConstraint {
+	return r.projectConflicts[projectName]
+}
+
 func (r *Resolver) setup(ctx context.Context, rootDeps []Dependency) error {
 	// 1.
 	// Discover projects and constraints that are part of the dependency tree.
+	// Forced constraints replace all other constraints on their project.
+	for _, force := range r.overrides.Force {
+		r.projectConstraints[force.Name] = append(
+			r.projectConstraints[force.Name],
+			ResolverConstraint{
+				Origin:             rootProjectVersion,
+				SubjectProjectName: force.Name,
+				Constraints:        force.Constraints,
+			},
+		)
+	}
 	if err := r.walkProjectConstraints(ctx,
 		Project{
-			Name: "root",
+			Name: rootProjectVersion.Name,
 			Versions: []ProjectVersion{
 				{Dependencies: rootDeps},
 			},
 		}); err != nil {
 		return err
 	}
+	// r.projects grows while walking,
+	// so projects are visited breadth first and only once.
+	for i := 0; i < len(r.projects); i++ {
+		if err := r.walkProjectConstraints(ctx, r.projects[i]); err != nil {
+			return err
+		}
+	}
 
 	// 2.
-	// Assign each project version a literal for the SAT solver.
+	// Drop pre-releases, yanked and excluded versions that should not be considered
+	// and rank deprecated and retracted versions last.
+	for i := range r.projects {
+		r.projects[i].Versions = r.filterPrereleases(r.projects[i])
+		r.projects[i].Versions = r.filterYanked(r.projects[i])
+		// only exclusions of remaining candidates are reported.
+		r.projects[i].Versions = r.filterExcluded(r.projects[i])
+		sort.SliceStable(r.projects[i].Versions, func(a, b int) bool {
+			return !isDiscouraged(r.projects[i].Versions[a]) && isDiscouraged(r.projects[i].Versions[b])
+		})
+	}
+
+	// 3.
+	// Assign each project and project version a literal for the SAT solver.
+	// The project literals are true, if the project is installed in the target environment.
+	// Versions are shared by all target environments, so every environment uses the same version.
+	// Capabilities get literals that are true, if they are installed in the target environment.
+	environments := r.targetEnvironments()
+	for name := range r.capabilityProviders {
+		r.capabilitiesToLiterals[name] = r.environmentLiterals()
+	}
 	for _, project := range r.projects {
-		// CONSTRAINT: We want at least one version of each project
+		r.projectsToLiterals[project.Name] = r.environmentLiterals()
 		for _, pv := range project.Versions {
-			lit := r.gini.Lit()
-			r.projectVersionsToLiterals[ResolverProjectVersion{
+			rpv := ResolverProjectVersion{
 				Name:    project.Name,
 				Version: pv.Version.String(),
-			}] = lit
-			r.gini.Add(lit)
+			}
+			r.projectVersionsToLiterals[rpv] = r.gini.Lit()
+			for _, feature := range pv.Features {
+				rpv.Feature = feature.Name
+				r.featuresToLiterals[rpv] = r.gini.Lit()
+			}
+		}
+	}
+
+	// 4.
+	// Encode constraints as clauses.
+	for _, project := range r.projects {
+		// CONSTRAINT: An installed project has at least one version
+		installedLits := r.projectsToLiterals[project.Name]
+		for _, installedLit := range installedLits {
+			r.gini.Add(installedLit.Not())
+			for _, pv := range project.Versions {
+				r.gini.Add(r.projectVersionsToLiterals[ResolverProjectVersion{
+					Name:    project.Name,
+					Version: pv.Version.String(),
+				}])
+			}
+			r.gini.Add(z.LitNull)
+		}
+
+		// CONSTRAINT: A project with a selected version is installed in some environment
+		for _, pv := range project.Versions {
+			r.gini.Add(r.projectVersionsToLiterals[ResolverProjectVersion{
+				Name:    project.Name,
+				Version: pv.Version.String(),
+			}].Not())
+			for _, installedLit := range installedLits {
+				r.gini.Add(installedLit)
+			}
+			r.gini.Add(z.LitNull)
+		}
+
+		// CONSTRAINT: Enabled features require their project version
+		for _, pv := range project.Versions {
+			rpv := ResolverProjectVersion{
+				Name:    project.Name,
+				Version: pv.Version.String(),
+			}
+			pvLit := r.projectVersionsToLiterals[rpv]
+			for _, feature := range pv.Features {
+				rpv.Feature = feature.Name
+				r.gini.Add(r.featuresToLiterals[rpv].Not())
+				r.gini.Add(pvLit)
+				r.gini.Add(z.LitNull)
+			}
+		}
+
+		// CONSTRAINT: A requested pre-release needs a selected origin requesting it
+		if !r.prereleasesAllowed(project.Name) {
+			for _, pv := range project.Versions {
+				if !isPrerelease(pv.Version) {
+					continue
+				}
+				origins, root := r.requestedBy(project, pv.Version, requestsPrerelease)
+				if root {
+					continue
+				}
+				r.gini.Add(r.projectVersionsToLiterals[ResolverProjectVersion{
+					Name:    project.Name,
+					Version: pv.Version.String(),
+				}].Not())
+				for _, lit := range origins {
+					r.gini.Add(lit)
+				}
+				r.gini.Add(z.LitNull)
+			}
+		}
+
+		// CONSTRAINT: A yanked version, that is not locked, needs a selected origin pinning it
+		for _, pv := range project.Versions {
+			if !pv.Yanked || r.locked(project.Name, pv.Version) {
+				continue
+			}
+			origins, root := r.requestedBy(project, pv.Version, pinsVersion)
+			if root {
+				continue
+			}
+			r.gini.Add(r.projectVersionsToLiterals[ResolverProjectVersion{
+				Name:    project.Name,
+				Version: pv.Version.String(),
+			}].Not())
+			for _, lit := range origins {
+				r.gini.Add(lit)
+			}
+			r.gini.Add(z.LitNull)
 		}
-		r.gini.Add(z.LitNull)
 
 		// CONSTRAINT: We want at MOST one version of each project
 		for _, pv := range project.Versions {
@@ -123,6 +499,10 @@ func (r *Resolver) setup(ctx context.Context, rootDeps []Dependency) error {
 					// We don't want to exclude ourselves!
 					continue
 				}
+				if r.uniquenessKey(project.Name, pv.Version) != r.uniquenessKey(project.Name, otherPV.Version) {
+					// versions with different keys may coexist.
+					continue
+				}
 				otherPVLit := r.projectVersionsToLiterals[otherRPV]
 				r.gini.Add(pvLit.Not())
 				r.gini.Add(otherPVLit.Not())
@@ -133,75 +513,457 @@ func (r *Resolver) setup(ctx context.Context, rootDeps []Dependency) error {
 		// CONSTRAINT: Process actual dependency constraints
 		constraints := r.projectConstraints[project.Name]
 		for _, constraint := range constraints {
+			srcLit, ok := r.originLiteral(constraint.Origin)
+			if !ok {
+				// origin version is not considered.
+				continue
+			}
+			and := project.Migrations.Constraint(ConstraintAND(constraint.Constraints))
+			if _, ok := r.uniquenessKeys[project.Name]; ok {
+				// CONSTRAINT: One of the installed versions matches,
+				// others may be installed for other dependents
+				for _, env := range r.matchingEnvironments(constraint.Marker) {
+					r.addOriginNot(constraint.Origin, srcLit, env)
+					r.gini.Add(installedLits[env].Not())
+					for _, pv := range project.Versions {
+						if and.Matches(pv.Version) {
+							r.gini.Add(r.projectVersionsToLiterals[ResolverProjectVersion{
+								Name:    project.Name,
+								Version: pv.Version.String(),
+							}])
+						}
+					}
+					r.gini.Add(z.LitNull)
+				}
+				continue
+			}
 			for _, pv := range project.Versions {
-				if ConstraintAND(constraint.Constraints).Matches(pv.Version) {
+				if and.Matches(pv.Version) {
 					// matches -> unconstrained!
 					continue
 				}
-				srcLit := r.projectVersionsToLiterals[constraint.Origin]
-				if srcLit != 0 {
-					r.gini.Add(srcLit.Not())
+				for _, env := range r.matchingEnvironments(constraint.Marker) {
+					r.addOriginNot(constraint.Origin, srcLit, env)
+					r.gini.Add(r.projectVersionsToLiterals[ResolverProjectVersion{
+						Name:    constraint.SubjectProjectName,
+						Version: pv.Version.String(),
+					}].Not())
+					r.gini.Add(z.LitNull)
+				}
+			}
+		}
+
+		// CONSTRAINT: Versions matching a conflict exclude the origin in the same environment
+		for _, conflict := range r.projectConflicts[project.Name] {
+			srcLit, ok := r.originLiteral(conflict.Origin)
+			if !ok {
+				// origin version is not considered.
+				continue
+			}
+			and := project.Migrations.Constraint(ConstraintAND(conflict.Constraints))
+			for _, pv := range project.Versions {
+				if !and.Matches(pv.Version) {
+					continue
+				}
+				for _, env := range r.matchingEnvironments(conflict.Marker) {
+					r.addOriginNot(conflict.Origin, srcLit, env)
+					r.gini.Add(installedLits[env].Not())
+					r.gini.Add(r.projectVersionsToLiterals[ResolverProjectVersion{
+						Name:    conflict.SubjectProjectName,
+						Version: pv.Version.String(),
+					}].Not())
+					r.gini.Add(z.LitNull)
+				}
+			}
+		}
+	}
+
+	projectsByName := map[string]Project{}
+	for _, project := range r.projects {
+		projectsByName[project.Name] = project
+	}
+
+	// CONSTRAINT: Installed capabilities are provided by a selected version
+	// and constraints on capabilities are satisfied by provided versions.
+	providerLiterals := map[string]map[string][]z.Lit{}
+	for name := range r.capabilityProviders {
+		providerLiterals[name] = r.encodeCapability(name)
+	}
+
+	// CONSTRAINT: A selected version installs its dependencies in the same environment
+	for origin, deps := range r.projectDependencies {
+		srcLit, ok := r.originLiteral(origin)
+		if !ok {
+			// origin version is not considered.
+			continue
+		}
+		for _, dep := range deps {
+			if !isRequired(dep) {
+				continue
+			}
+			for _, env := range r.matchingEnvironments(dep.Marker) {
+				r.addOriginNot(origin, srcLit, env)
+				if lits, ok := r.capabilitiesToLiterals[dep.Name]; ok {
+					r.gini.Add(lits[env])
+				} else {
+					r.gini.Add(r.projectsToLiterals[dep.Name][env])
 				}
-				r.gini.Add(r.projectVersionsToLiterals[ResolverProjectVersion{
-					Name:    constraint.SubjectProjectName,
-					Version: pv.Version.String(),
-				}].Not())
 				r.gini.Add(z.LitNull)
 			}
 		}
 	}
 
+	// CONSTRAINT: Features requested by a dependency are enabled, if the project is installed
+	for _, request := range r.featureRequests {
+		srcLit, ok := r.originLiteral(request.origin)
+		if !ok {
+			// origin version is not considered.
+			continue
+		}
+		project, ok := projectsByName[request.dependency.Name]
+		if !ok {
+			// features only apply to projects.
+			continue
+		}
+		for _, feature := range request.dependency.Features {
+			for _, env := range r.matchingEnvironments(request.dependency.Marker) {
+				r.addOriginNot(request.origin, srcLit, env)
+				r.gini.Add(r.projectsToLiterals[project.Name][env].Not())
+				for _, pv := range project.Versions {
+					lit, ok := r.featuresToLiterals[ResolverProjectVersion{
+						Name:    project.Name,
+						Version: pv.Version.String(),
+						Feature: feature,
+					}]
+					if ok {
+						r.gini.Add(lit)
+					}
+				}
+				r.gini.Add(z.LitNull)
+			}
+		}
+	}
+
+	// 5.
+	// Order decisions for the greedy pass:
+	// capabilities pick their provider before the providers pick their version.
+	// A capability first tries to be provided by a single provider,
+	// before allowing other providers to be installed as well.
+	// Providers are picked for each target environment on its own.
+	for _, decision := range r.discoveryOrder {
+		name := decision.name
+		if decision.capability {
+			providers := r.capabilityProviders[name]
+			for env := range environments {
+				envDecision := resolverDecision{
+					name:       r.decisionName(name, env),
+					capability: true,
+					candidates: [][]z.Lit{{r.capabilitiesToLiterals[name][env].Not()}},
+				}
+				for _, provider := range providers {
+					lits, ok := providerLiterals[name][provider]
+					if !ok {
+						continue
+					}
+					exclusive := []z.Lit{lits[env]}
+					for _, other := range providers {
+						if other != provider {
+							exclusive = append(exclusive, r.projectsToLiterals[other][env].Not())
+						}
+					}
+					envDecision.candidates = append(envDecision.candidates, exclusive)
+				}
+				for _, provider := range providers {
+					if lits, ok := providerLiterals[name][provider]; ok {
+						envDecision.candidates = append(envDecision.candidates, []z.Lit{lits[env]})
+					}
+				}
+				r.decisions = append(r.decisions, envDecision)
+			}
+			continue
+		}
+
+		var notInstalled []z.Lit
+		for _, lit := range r.projectsToLiterals[name] {
+			notInstalled = append(notInstalled, lit.Not())
+		}
+		decision.candidates = [][]z.Lit{notInstalled}
+		for _, pv := range projectsByName[name].Versions {
+			decision.candidates = append(decision.candidates, []z.Lit{
+				r.projectVersionsToLiterals[ResolverProjectVersion{
+					Name:    name,
+					Version: pv.Version.String(),
+				}],
+			})
+		}
+		r.decisions = append(r.decisions, decision)
+
+		if _, ok := r.uniquenessKeys[name]; ok {
+			// additional versions are only installed, if required,
+			// with a decision for each group of versions sharing a key.
+			var keys []string
+			groups := map[string][]z.Lit{}
+			for _, pv := range projectsByName[name].Versions {
+				key := r.uniquenessKey(name, pv.Version)
+				if _, ok := groups[key]; !ok {
+					keys = append(keys, key)
+				}
+				groups[key] = append(groups[key], r.projectVersionsToLiterals[ResolverProjectVersion{
+					Name:    name,
+					Version: pv.Version.String(),
+				}])
+			}
+			for _, key := range keys {
+				keyDecision := resolverDecision{name: name + "@" + key}
+				var notInstalled []z.Lit
+				for _, lit := range groups[key] {
+					notInstalled = append(notInstalled, lit.Not())
+				}
+				keyDecision.candidates = [][]z.Lit{notInstalled}
+				for _, lit := range groups[key] {
+					keyDecision.candidates = append(keyDecision.candidates, []z.Lit{lit})
+				}
+				r.decisions = append(r.decisions, keyDecision)
+			}
+		}
+
+		if len(environments) > 1 {
+			// leave the project out of environments that don't need it.
+			for env, lit := range r.projectsToLiterals[name] {
+				r.decisions = append(r.decisions, resolverDecision{
+					name:       r.decisionName(name, env),
+					candidates: [][]z.Lit{{lit.Not()}, {lit}},
+				})
+			}
+		}
+	}
+	// Features are only enabled, if required.
+	for _, project := range r.projects {
+		for _, pv := range project.Versions {
+			for _, feature := range pv.Features {
+				rpv := ResolverProjectVersion{
+					Name:    project.Name,
+					Version: pv.Version.String(),
+					Feature: feature.Name,
+				}
+				lit := r.featuresToLiterals[rpv]
+				r.decisions = append(r.decisions, resolverDecision{
+					name:       rpv.String(),
+					candidates: [][]z.Lit{{lit.Not()}, {lit}},
+				})
+			}
+		}
+	}
+
 	return nil
 }
 
+// Encodes clauses for a capability and returns literals for each project providing the capability,
+// that are true, if the capability is provided by the project in the target environment.
+func (r *Resolver) encodeCapability(name string) map[string][]z.Lit {
+	type provided struct {
+		lit     z.Lit
+		version Version
+	}
+	byProject := map[string][]provided{}
+	for _, project := range r.projects {
+		for _, pv := range project.Versions {
+			capabilities := append(append([]Capability{}, pv.Provides...), pv.Replaces...)
+			if project.Name == name {
+				// projects provide themselves.
+				capabilities = append(capabilities, Capability{Name: name, Version: pv.Version})
+			}
+			for _, c := range capabilities {
+				if c.Name != name {
+					continue
+				}
+				byProject[project.Name] = append(byProject[project.Name], provided{
+					lit: r.projectVersionsToLiterals[ResolverProjectVersion{
+						Name:    project.Name,
+						Version: pv.Version.String(),
+					}],
+					version: c.Version,
+				})
+			}
+		}
+	}
+
+	// CONSTRAINT: A capability provided by a project is provided by a selected version of it,
+	// that is installed in the same environment
+	providerLits := map[string][]z.Lit{}
+	for project, providers := range byProject {
+		lits := r.environmentLiterals()
+		for env, providerLit := range lits {
+			r.gini.Add(providerLit.Not())
+			r.gini.Add(r.projectsToLiterals[project][env])
+			r.gini.Add(z.LitNull)
+
+			r.gini.Add(providerLit.Not())
+			for _, p := range providers {
+				r.gini.Add(p.lit)
+			}
+			r.gini.Add(z.LitNull)
+		}
+		providerLits[project] = lits
+	}
+
+	// CONSTRAINT: An installed capability is provided by a project
+	for env, capabilityLit := range r.capabilitiesToLiterals[name] {
+		r.gini.Add(capabilityLit.Not())
+		for _, lits := range providerLits {
+			r.gini.Add(lits[env])
+		}
+		r.gini.Add(z.LitNull)
+	}
+
+	// CONSTRAINT: Constraints on an installed capability are satisfied by a provided version
+	for _, constraint := range r.projectConstraints[name] {
+		srcLit, ok := r.originLiteral(constraint.Origin)
+		if !ok {
+			// origin version is not considered.
+			continue
+		}
+		and := ConstraintAND(constraint.Constraints)
+		for _, env := range r.matchingEnvironments(constraint.Marker) {
+			r.addOriginNot(constraint.Origin, srcLit, env)
+			r.gini.Add(r.capabilitiesToLiterals[name][env].Not())
+			for _, providers := range byProject {
+				for _, p := range providers {
+					if p.version != nil && and.Matches(p.version) {
+						r.gini.Add(p.lit)
+					}
+				}
+			}
+			r.gini.Add(z.LitNull)
+		}
+	}
+	return providerLits
+}
+
+// Adds the negated literals of a constraint origin in the given target environment to the current clause,
+// so the clause only applies if the origin is installed in the environment.
+// The root origin is always installed.
+func (r *Resolver) addOriginNot(origin ResolverProjectVersion, originLit z.Lit, env int) {
+	if origin == rootProjectVersion {
+		return
+	}
+	r.gini.Add(r.projectsToLiterals[origin.Name][env].Not())
+	r.gini.Add(originLit.Not())
+}
+
+// Returns the uniqueness key of a version of the given project,
+// all versions share the same key, if the project has no UniquenessKey.
+func (r *Resolver) uniquenessKey(projectName string, v Version) string {
+	key, ok := r.uniquenessKeys[projectName]
+	if !ok {
+		return ""
+	}
+	return key(v)
+}
+
+// Returns a new literal for each target environment.
+func (r *Resolver) environmentLiterals() []z.Lit {
+	lits := make([]z.Lit, len(r.targetEnvironments()))
+	for i := range lits {
+		lits[i] = r.gini.Lit()
+	}
+	return lits
+}
+
+// Returns the environments to resolve for.
+// Without target environments, a single environment ignoring all markers is used.
+func (r *Resolver) targetEnvironments() []Environment {
+	if len(r.environments) == 0 {
+		return []Environment{nil}
+	}
+	return r.environments
+}
+
+// Returns the indexes of the target environments the given marker matches.
+func (r *Resolver) matchingEnvironments(marker Marker) []int {
+	var envs []int
+	for i, env := range r.targetEnvironments() {
+		if env == nil || marker == nil || marker.Matches(env) {
+			envs = append(envs, i)
+		}
+	}
+	return envs
+}
+
+// Names a decision for the target environment with the given index.
+func (r *Resolver) decisionName(name string, env int) string {
+	if len(r.environments) <= 1 {
+		return name
+	}
+	return fmt.Sprintf("%s on %s", name, r.environments[env])
+}
+
+// Returns the literal of a project version or feature that is the origin of a constraint.
+// The root origin has no literal, but is always considered.
+func (r *Resolver) originLiteral(origin ResolverProjectVersion) (z.Lit, bool) {
+	if origin == rootProjectVersion {
+		return 0, true
+	}
+	if len(origin.Feature) != 0 {
+		lit, ok := r.featuresToLiterals[origin]
+		return lit, ok
+	}
+	lit, ok := r.projectVersionsToLiterals[origin]
+	return lit, ok
+}
+
 func (r *Resolver) resolve(ctx context.Context, rootDeps []Dependency) error {
 	// Shortcut, is there any combination that works?
 	if r.gini.Solve() != 1 {
 		return fmt.Errorf("nosat!")
 	}
 
-	// selectedProjectVersion := map[string]Version{}
-	selectedProjectVersion := map[string]Version{}
+	if len(r.decisions) == 0 {
+		// nothing to install.
+		return nil
+	}
+
+	// Literals assumed for each decision that has been taken, by decision index.
+	selectedLiterals := map[int][]z.Lit{}
 	var (
-		projectIndex        int
-		projectVersionIndex int
+		decisionIndex  int
+		candidateIndex int
 	)
 
 	// Find _latest_ version of all components that still satisfy the model, by
+	// first trying to leave out the project, then
 	// starting with the latest version of each project and testing older and older versions.
+	// Capabilities try their providers in order of priority instead of versions.
 tryAgain:
-	// select version to try:
-	if projectIndex >= len(r.projects) {
+	// select candidate to try:
+	if decisionIndex >= len(r.decisions) {
 		return fmt.Errorf("NOSAT! out of projects!")
 	}
-	project := r.projects[projectIndex]
-	if _, ok := selectedProjectVersion[project.Name]; !ok {
-		if projectVersionIndex >= len(project.Versions) {
-			return fmt.Errorf("NOSAT! out of versions for %s", project.Name)
+	decision := r.decisions[decisionIndex]
+	if _, ok := selectedLiterals[decisionIndex]; !ok {
+		if candidateIndex >= len(decision.candidates) {
+			return fmt.Errorf("NOSAT! out of versions for %s", decision.name)
 		}
 
-		selectedProjectVersion[project.Name] = project.Versions[projectVersionIndex].Version
+		selectedLiterals[decisionIndex] = decision.candidates[candidateIndex]
 	}
 
-	for projectName, version := range selectedProjectVersion {
-		r.gini.Assume(r.projectVersionsToLiterals[ResolverProjectVersion{
-			Name:    projectName,
-			Version: version.String(),
-		}])
+	for _, lits := range selectedLiterals {
+		r.gini.Assume(lits...)
 	}
 
 	if r.gini.Solve() != 1 {
-		// select next version when UNSAT
-		delete(selectedProjectVersion, project.Name)
-		projectVersionIndex++
+		// select next candidate when UNSAT
+		delete(selectedLiterals, decisionIndex)
+		candidateIndex++
 		goto tryAgain
 	}
 
-	// do we have a solution for all projects?
-	if len(selectedProjectVersion) != len(r.projects) {
-		// add next project
-		projectIndex++
-		projectVersionIndex = 0
+	// do we have a solution for all decisions?
+	if len(selectedLiterals) != len(r.decisions) {
+		// add next decision
+		decisionIndex++
+		candidateIndex = 0
 		goto tryAgain
 	}
 
@@ -212,47 +974,325 @@ tryAgain:
 		}
 	}
 	r.resolved = resolved
+
+	for rpv, lit := range r.featuresToLiterals {
+		if r.gini.Value(lit) {
+			pv := ResolverProjectVersion{Name: rpv.Name, Version: rpv.Version}
+			r.enabledFeatures[pv] = append(r.enabledFeatures[pv], rpv.Feature)
+		}
+	}
+	for _, features := range r.enabledFeatures {
+		sort.Strings(features)
+	}
+
+	for origin, deps := range r.projectDependencies {
+		srcLit, ok := r.originLiteral(origin)
+		if !ok || (srcLit != 0 && !r.gini.Value(srcLit)) {
+			continue
+		}
+		for _, dep := range deps {
+			if !r.installedWith(origin, dep) {
+				continue
+			}
+			if to, ok := r.linkTarget(dep); ok {
+				r.links = append(r.links, ResolverLink{From: origin, To: to})
+			}
+		}
+	}
+	sort.Slice(r.links, func(i, j int) bool {
+		if r.links[i].From != r.links[j].From {
+			return r.links[i].From.String() < r.links[j].From.String()
+		}
+		return r.links[i].To.String() < r.links[j].To.String()
+	})
+
+	for _, project := range r.projects {
+		for env, lit := range r.projectsToLiterals[project.Name] {
+			if len(r.environments) != 0 && r.gini.Value(lit) {
+				r.installedEnvironments[project.Name] = append(
+					r.installedEnvironments[project.Name], r.environments[env])
+			}
+		}
+	}
+
+	for _, project := range r.projects {
+		for _, pv := range project.Versions {
+			rpv := ResolverProjectVersion{Name: project.Name, Version: pv.Version.String()}
+			if !r.gini.Value(r.projectVersionsToLiterals[rpv]) {
+				continue
+			}
+			r.warnings = append(r.warnings, versionWarnings(rpv, pv)...)
+			for _, replaced := range pv.Replaces {
+				if _, ok := r.capabilityProviders[replaced.Name]; ok {
+					r.substitutions = append(r.substitutions, ResolverSubstitution{
+						Replaced: replaced.Name,
+						By:       rpv,
+					})
+				}
+			}
+		}
+	}
+	sort.SliceStable(r.warnings, func(i, j int) bool {
+		return r.warnings[i].ProjectVersion.Name < r.warnings[j].ProjectVersion.Name
+	})
+
+	// forced constraints change the result, even if they replace no other constraint.
+	for _, force := range r.overrides.Force {
+		for _, rpv := range r.resolved {
+			if rpv.Name == force.Name {
+				r.appliedOverrides = append(r.appliedOverrides, ResolverOverride{
+					Kind:       OverrideForce,
+					Constraint: ResolverConstraint{SubjectProjectName: force.Name},
+					Forced:     force.Constraints,
+				})
+				break
+			}
+		}
+	}
+	for _, override := range r.overridden {
+		if override.Kind != OverrideExclude {
+			srcLit, ok := r.originLiteral(override.Constraint.Origin)
+			if !ok || (srcLit != 0 && !r.gini.Value(srcLit)) {
+				// origin version is not selected.
+				continue
+			}
+		}
+		r.appliedOverrides = append(r.appliedOverrides, override)
+	}
 	return nil
 }
 
+// Returns the versions of the project that are not excluded by overrides.
+func (r *Resolver) filterExcluded(project Project) []ProjectVersion {
+	var versions []ProjectVersion
+	for _, pv := range project.Versions {
+		rpv := ResolverProjectVersion{Name: project.Name, Version: pv.Version.String()}
+		if r.overrides.excludes(rpv) {
+			r.overridden = append(r.overridden, ResolverOverride{Kind: OverrideExclude, Excluded: rpv})
+			continue
+		}
+		versions = append(versions, pv)
+	}
+	return versions
+}
+
+// Returns the versions of the project without yanked versions,
+// unless they are pinned exactly by a constraint or locked.
+// Pinned yanked versions additionally need a selected origin, see requestedBy.
+func (r *Resolver) filterYanked(project Project) []ProjectVersion {
+	var versions []ProjectVersion
+	for _, pv := range project.Versions {
+		if !pv.Yanked || r.locked(project.Name, pv.Version) || r.requested(project, pv.Version, pinsVersion) {
+			versions = append(versions, pv)
+		}
+	}
+	return versions
+}
+
+func (r *Resolver) locked(projectName string, v Version) bool {
+	_, ok := r.lockedVersions[ResolverProjectVersion{Name: projectName, Version: v.String()}]
+	return ok
+}
+
+// A version is pinned, when a constraint only matches this exact version.
+func pinsVersion(project Project, constraint ResolverConstraint, v Version) bool {
+	return pins(ConstraintAND(constraint.Constraints), v)
+}
+
+func pins(c VersionConstraint, v Version) bool {
+	switch c := c.(type) {
+	case ExactConstraint:
+		exact, ok := c.ExactVersion()
+		return ok && exact.Equal(v)
+	case ConstraintAND:
+		for _, con := range c {
+			if pins(con, v) {
+				return true
+			}
+		}
+	case ConstraintOR:
+		for _, con := range c {
+			if pins(con, v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Deprecated and retracted versions are only selected, if no other version fits.
+func isDiscouraged(pv ProjectVersion) bool {
+	return len(pv.Deprecated) != 0 || len(pv.Retracted) != 0
+}
+
+func versionWarnings(rpv ResolverProjectVersion, pv ProjectVersion) []ResolverWarning {
+	var warnings []ResolverWarning
+	if pv.Yanked {
+		warnings = append(warnings, ResolverWarning{ProjectVersion: rpv, Message: "yanked"})
+	}
+	if len(pv.Deprecated) != 0 {
+		warnings = append(warnings, ResolverWarning{ProjectVersion: rpv, Message: "deprecated: " + pv.Deprecated})
+	}
+	if len(pv.Retracted) != 0 {
+		warnings = append(warnings, ResolverWarning{ProjectVersion: rpv, Message: "retracted: " + pv.Retracted})
+	}
+	return warnings
+}
+
+// Returns true, if the dependency applies to a target environment the origin is installed in.
+func (r *Resolver) installedWith(origin ResolverProjectVersion, dep Dependency) bool {
+	for _, env := range r.matchingEnvironments(dep.Marker) {
+		if origin == rootProjectVersion || r.gini.Value(r.projectsToLiterals[origin.Name][env]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the latest selected version satisfying the dependency.
+// Versions of the project itself are preferred over providers of it.
+func (r *Resolver) linkTarget(dep Dependency) (ResolverProjectVersion, bool) {
+	for _, project := range r.projects {
+		if project.Name != dep.Name {
+			continue
+		}
+		and := project.Migrations.Constraint(ConstraintAND(dep.Constraints))
+		for _, pv := range project.Versions {
+			rpv := ResolverProjectVersion{Name: project.Name, Version: pv.Version.String()}
+			if r.gini.Value(r.projectVersionsToLiterals[rpv]) && and.Matches(pv.Version) {
+				return rpv, true
+			}
+		}
+	}
+
+	and := ConstraintAND(dep.Constraints)
+	for _, project := range r.projects {
+		for _, pv := range project.Versions {
+			rpv := ResolverProjectVersion{Name: project.Name, Version: pv.Version.String()}
+			if !r.gini.Value(r.projectVersionsToLiterals[rpv]) {
+				continue
+			}
+			for _, c := range append(append([]Capability{}, pv.Provides...), pv.Replaces...) {
+				if c.Name == dep.Name &&
+					(len(dep.Constraints) == 0 || c.Version != nil && and.Matches(c.Version)) {
+					return rpv, true
+				}
+			}
+		}
+	}
+	return ResolverProjectVersion{}, false
+}
+
+// Returns true, if the pre-release policy allows all pre-releases of the project.
+func (r *Resolver) prereleasesAllowed(projectName string) bool {
+	_, ok := r.prereleaseProjects[projectName]
+	return ok || r.prereleasePolicy == PrereleasesAllowed
+}
+
+// Returns the versions of the project that are allowed by the pre-release policy
+// or requested by some constraint.
+// Requested pre-releases additionally need a selected origin, see requestedBy.
+func (r *Resolver) filterPrereleases(project Project) []ProjectVersion {
+	if r.prereleasesAllowed(project.Name) {
+		return project.Versions
+	}
+
+	var versions []ProjectVersion
+	for _, pv := range project.Versions {
+		if !isPrerelease(pv.Version) || r.requested(project, pv.Version, requestsPrerelease) {
+			versions = append(versions, pv)
+		}
+	}
+	return versions
+}
+
+// Decides whether a constraint on the project explicitly requests the version.
+type constraintRequest func(project Project, constraint ResolverConstraint, v Version) bool
+
+// A pre-release is requested, when it matches a constraint that references a pre-release version.
+func requestsPrerelease(project Project, constraint ResolverConstraint, v Version) bool {
+	and := project.Migrations.Constraint(ConstraintAND(constraint.Constraints))
+	for _, cv := range and.Versions() {
+		if isPrerelease(cv) && and.Matches(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true, if any constraint on the project requests the version.
+func (r *Resolver) requested(project Project, v Version, requests constraintRequest) bool {
+	for _, constraint := range r.projectConstraints[project.Name] {
+		if requests(project, constraint, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the literals of the origins requesting the version.
+// The second return value is true, if the root requests it, so it needs no selected origin.
+func (r *Resolver) requestedBy(project Project, v Version, requests constraintRequest) ([]z.Lit, bool) {
+	var lits []z.Lit
+	for _, constraint := range r.projectConstraints[project.Name] {
+		if !requests(project, constraint, v) {
+			continue
+		}
+		if constraint.Origin == rootProjectVersion {
+			return nil, true
+		}
+		if lit, ok := r.originLiteral(constraint.Origin); ok {
+			lits = append(lits, lit)
+		}
+	}
+	return lits, false
+}
+
 func (r *Resolver) walkProjectConstraints(
 	ctx context.Context,
 	project Project,
 ) error {
 
 	for _, pv := range project.Versions {
-		for _, dep := range pv.Dependencies {
-			depProject, err := r.db.Get(ctx, dep.Name)
-			if err != nil {
-				return err
-			}
+		origin := ResolverProjectVersion{Name: project.Name}
+		if pv.Version != nil {
+			origin.Version = pv.Version.String()
+		}
+
+		for _, replaced := range pv.Replaces {
+			r.projectConflicts[replaced.Name] = append(
+				r.projectConflicts[replaced.Name],
+				ResolverConstraint{
+					Origin:             origin,
+					SubjectProjectName: replaced.Name,
+					Conflict:           true,
+				},
+			)
+		}
 
-			if _, ok := r.projectConstraints[dep.Name]; !ok {
-				// ensure key is present, even if unconstrained.
-				r.projectConstraints[dep.Name] = nil
-				r.projects = append(r.projects, depProject)
+		for _, conflict := range pv.Conflicts {
+			if !r.matchesEnvironment(conflict.Marker) {
+				continue
 			}
+			r.projectConflicts[conflict.Name] = append(
+				r.projectConflicts[conflict.Name],
+				ResolverConstraint{
+					Origin:             origin,
+					SubjectProjectName: conflict.Name,
+					Constraints:        conflict.Constraints,
+					Conflict:           true,
+					Marker:             conflict.Marker,
+				},
+			)
+		}
 
-			if len(dep.Constraints) != 0 {
-				var v string
-				if pv.Version != nil {
-					v = pv.Version.String()
-				}
-				r.projectConstraints[dep.Name] = append(
-					r.projectConstraints[dep.Name],
-					ResolverConstraint{
-						Origin: ResolverProjectVersion{
-							Name:    project.Name,
-							Version: v,
-						},
-						SubjectProjectName: dep.Name,
-						Constraints:        dep.Constraints,
-					},
-				)
-			}
-
-			if err := r.walkProjectConstraints(
-				ctx, depProject); err != nil {
+		if err := r.walkDependencies(ctx, origin, pv.Dependencies); err != nil {
+			return err
+		}
+		for _, feature := range pv.Features {
+			featureOrigin := origin
+			featureOrigin.Feature = feature.Name
+			if err := r.walkDependencies(ctx, featureOrigin, feature.Dependencies); err != nil {
 				return err
 			}
 		}
@@ -260,6 +1300,141 @@ func (r *Resolver) walkProjectConstraints(
 	return nil
 }
 
+func (r *Resolver) walkDependencies(
+	ctx context.Context,
+	origin ResolverProjectVersion,
+	deps []Dependency,
+) error {
+	for _, dep := range deps {
+		if !r.includesKind(origin, dep.Kind) || !r.matchesEnvironment(dep.Marker) {
+			continue
+		}
+		if len(dep.Constraints) != 0 {
+			constraint := ResolverConstraint{
+				Origin:             origin,
+				SubjectProjectName: dep.Name,
+				Constraints:        dep.Constraints,
+				Marker:             dep.Marker,
+			}
+			if override, ok := r.overrides.constraintOverride(constraint); ok {
+				r.overridden = append(r.overridden, override)
+				// links follow the constraints left after the override.
+				dep.Constraints = override.Forced
+			} else {
+				r.projectConstraints[dep.Name] = append(r.projectConstraints[dep.Name], constraint)
+			}
+		}
+		if len(dep.Features) != 0 {
+			r.featureRequests = append(r.featureRequests, resolverFeatureRequest{
+				origin:     origin,
+				dependency: dep,
+			})
+		}
+
+		r.projectDependencies[origin] = append(r.projectDependencies[origin], dep)
+		if !isRequired(dep) {
+			// optional and peer dependencies don't pull in the project.
+			continue
+		}
+		if err := r.discover(ctx, dep.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns true, if the dependency installs the project.
+func isRequired(dep Dependency) bool {
+	return !dep.Optional && dep.Kind != DependencyPeer
+}
+
+// Returns true, if dependencies of the given kind declared by the origin should be resolved.
+// Test and dev dependencies only apply to the root, not to the projects it depends on.
+func (r *Resolver) includesKind(origin ResolverProjectVersion, kind DependencyKind) bool {
+	if (kind == DependencyTest || kind == DependencyDev) && origin != rootProjectVersion {
+		return false
+	}
+	if r.dependencyKinds == nil || kind == DependencyPeer {
+		return true
+	}
+	_, ok := r.dependencyKinds[kind]
+	return ok
+}
+
+// Returns true, if a dependency with the given marker applies to any target environment.
+func (r *Resolver) matchesEnvironment(marker Marker) bool {
+	return len(r.matchingEnvironments(marker)) != 0
+}
+
+// Adds the project and all providers of the capability with the given name.
+// Projects that are provided or replaced by other projects are handled as capabilities,
+// that are also provided by the project itself.
+func (r *Resolver) discover(ctx context.Context, name string) error {
+	if _, ok := r.discovered[name]; ok {
+		return nil
+	}
+	r.discovered[name] = struct{}{}
+
+	project, err := r.db.Get(ctx, name)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	found := err == nil
+
+	providers, perr := r.db.Providers(ctx, name)
+	if perr != nil {
+		return perr
+	}
+	if !found && len(providers) == 0 {
+		return err
+	}
+	if len(providers) != 0 {
+		providerNames := r.prioritizeProviders(name, providers)
+		if found {
+			// prefer replacements over the project itself.
+			providerNames = append(providerNames, name)
+		}
+		r.capabilityProviders[name] = providerNames
+		r.discoveryOrder = append(r.discoveryOrder, resolverDecision{name: name, capability: true})
+	}
+	if found {
+		r.addProject(project)
+	}
+	for _, provider := range providers {
+		r.addProject(provider)
+	}
+	return nil
+}
+
+func (r *Resolver) addProject(project Project) {
+	if _, ok := r.discoveredProjects[project.Name]; ok {
+		return
+	}
+	r.discoveredProjects[project.Name] = struct{}{}
+	r.discoveryOrder = append(r.discoveryOrder, resolverDecision{name: project.Name})
+	r.projects = append(r.projects, project)
+}
+
+// Returns the names of the providers in order of priority.
+func (r *Resolver) prioritizeProviders(capabilityName string, providers []Project) []string {
+	var names []string
+	prioritized := map[string]struct{}{}
+	for _, name := range r.providerPriorities[capabilityName] {
+		for _, provider := range providers {
+			if provider.Name == name {
+				names = append(names, name)
+				prioritized[name] = struct{}{}
+			}
+		}
+	}
+	for _, provider := range providers {
+		if _, ok := prioritized[provider.Name]; !ok {
+			names = append(names, provider.Name)
+		}
+	}
+	return names
+}
+
 type ResolverProjectVersionByName []ResolverProjectVersion
 
 func (a ResolverProjectVersionByName) Len() int           { return len(a) }
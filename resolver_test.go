@@ -19,7 +19,7 @@ func TestResolver(t *testing.T) {
 				{
 					Version: MustSemanticVersion("1.1.1"),
 					Dependencies: []Dependency{
-						{Name: "C", Constraints: []Constraint{
+						{Name: "C", Constraints: []VersionConstraint{
 							*NewConstraint(Equal, MustSemanticVersion("2.0.1")),
 						}},
 					},
@@ -27,7 +27,7 @@ func TestResolver(t *testing.T) {
 				{
 					Version: MustSemanticVersion("1.1.0"),
 					Dependencies: []Dependency{
-						{Name: "C", Constraints: []Constraint{
+						{Name: "C", Constraints: []VersionConstraint{
 							*NewConstraint(Equal, MustSemanticVersion("2.0.0")),
 						}},
 					},
@@ -40,7 +40,7 @@ func TestResolver(t *testing.T) {
 				{
 					Version: MustSemanticVersion("1.0.0"),
 					Dependencies: []Dependency{
-						{Name: "C", Constraints: []Constraint{
+						{Name: "C", Constraints: []VersionConstraint{
 							*NewConstraint(Equal, MustSemanticVersion("2.0.0")),
 						}},
 					},
@@ -104,7 +104,7 @@ func TestResolver_outputsLatest(t *testing.T) {
 				{
 					Version: MustSemanticVersion("1.1.1"),
 					Dependencies: []Dependency{
-						{Name: "C", Constraints: []Constraint{
+						{Name: "C", Constraints: []VersionConstraint{
 							*NewConstraint(Equal, MustSemanticVersion("2.0.1")),
 						}},
 					},
@@ -112,7 +112,7 @@ func TestResolver_outputsLatest(t *testing.T) {
 				{
 					Version: MustSemanticVersion("1.1.0"),
 					Dependencies: []Dependency{
-						{Name: "C", Constraints: []Constraint{
+						{Name: "C", Constraints: []VersionConstraint{
 							*NewConstraint(Equal, MustSemanticVersion("2.0.0")),
 						}},
 					},
@@ -120,7 +120,7 @@ func TestResolver_outputsLatest(t *testing.T) {
 				{
 					Version: MustSemanticVersion("1.0.0"),
 					Dependencies: []Dependency{
-						{Name: "C", Constraints: []Constraint{
+						{Name: "C", Constraints: []VersionConstraint{
 							*NewConstraint(Equal, MustSemanticVersion("2.0.0")),
 						}},
 					},
@@ -128,7 +128,7 @@ func TestResolver_outputsLatest(t *testing.T) {
 				{
 					Version: MustSemanticVersion("0.9.0"),
 					Dependencies: []Dependency{
-						{Name: "C", Constraints: []Constraint{
+						{Name: "C", Constraints: []VersionConstraint{
 							*NewConstraint(Equal, MustSemanticVersion("2.0.0")),
 						}},
 					},
@@ -141,7 +141,7 @@ func TestResolver_outputsLatest(t *testing.T) {
 				{
 					Version: MustSemanticVersion("1.1.0"),
 					Dependencies: []Dependency{
-						{Name: "C", Constraints: []Constraint{
+						{Name: "C", Constraints: []VersionConstraint{
 							*NewConstraint(Equal, MustSemanticVersion("2.0.0")),
 						}},
 					},
@@ -149,7 +149,7 @@ func TestResolver_outputsLatest(t *testing.T) {
 				{
 					Version: MustSemanticVersion("1.0.0"),
 					Dependencies: []Dependency{
-						{Name: "C", Constraints: []Constraint{
+						{Name: "C", Constraints: []VersionConstraint{
 							*NewConstraint(Equal, MustSemanticVersion("2.0.0")),
 						}},
 					},
@@ -214,7 +214,7 @@ func generateProjectDBEntries(projectNumber, projectVersions int) []Project {
 
 					pv.Dependencies = append(pv.Dependencies, Dependency{
 						Name:        fmt.Sprintf("P%d", di),
-						Constraints: []Constraint{*NewConstraint(Equal, v)},
+						Constraints: []VersionConstraint{*NewConstraint(Equal, v)},
 					})
 				}
 			}
@@ -259,3 +259,1364 @@ func BenchmarkResolve2_2(b *testing.B) { benchmarkResolveN(2, 2, b) }
 func BenchmarkResolve3_3(b *testing.B) { benchmarkResolveN(3, 3, b) }
 
 func BenchmarkResolve10_10(b *testing.B) { benchmarkResolveN(10, 10, b) }
+
+func TestResolver_prereleases(t *testing.T) {
+	var (
+		projectA = Project{
+			Name: "A",
+			Versions: []ProjectVersion{
+				{
+					Version: MustSemanticVersion("1.0.0"),
+					Dependencies: []Dependency{
+						{Name: "C"},
+					},
+				},
+			},
+		}
+		projectC = Project{
+			Name: "C",
+			Versions: []ProjectVersion{
+				{Version: MustSemanticVersion("2.1.0-rc.1")},
+				{Version: MustSemanticVersion("2.0.0")},
+				{Version: MustSemanticVersion("2.0.0-rc.1")},
+			},
+		}
+	)
+
+	ctx := context.Background()
+	db := NewInMemoryDB()
+	require.NoError(t, db.Add(ctx, projectA))
+	require.NoError(t, db.Add(ctx, projectC))
+
+	tests := []struct {
+		name     string
+		rootDeps []Dependency
+		opts     []ResolverOption
+		expected string
+	}{
+		{
+			name:     "excluded by default",
+			rootDeps: []Dependency{{Name: "A"}},
+			expected: "2.0.0",
+		},
+		{
+			name:     "allowed globally",
+			rootDeps: []Dependency{{Name: "A"}},
+			opts:     []ResolverOption{WithPrereleasePolicy(PrereleasesAllowed)},
+			expected: "2.1.0-rc.1",
+		},
+		{
+			name:     "allowed for project",
+			rootDeps: []Dependency{{Name: "A"}},
+			opts:     []ResolverOption{WithPrereleasesFor("C")},
+			expected: "2.1.0-rc.1",
+		},
+		{
+			name: "requested by constraint",
+			rootDeps: []Dependency{
+				{Name: "A"},
+				{Name: "C", Constraints: []VersionConstraint{
+					*NewConstraint(Equal, MustSemanticVersion("2.0.0-rc.1")),
+				}},
+			},
+			expected: "2.0.0-rc.1",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := NewResolver(db, test.opts...)
+			result, err := r.Resolve(ctx, test.rootDeps)
+			require.NoError(t, err)
+
+			sort.Sort(ResolverProjectVersionByName(result))
+			assert.Equal(t, []ResolverProjectVersion{
+				{Name: "A", Version: "1.0.0"},
+				{Name: "C", Version: test.expected},
+			}, result)
+		})
+	}
+}
+
+func TestResolver_prereleaseRequestedByUnselectedOrigin(t *testing.T) {
+	var (
+		projectA = Project{
+			Name: "A",
+			Versions: []ProjectVersion{
+				{
+					Version: MustSemanticVersion("2.0.0"),
+					Dependencies: []Dependency{
+						{Name: "C"},
+					},
+				},
+				{
+					Version: MustSemanticVersion("1.0.0"),
+					Dependencies: []Dependency{
+						{Name: "C", Constraints: []VersionConstraint{
+							*NewConstraint(GreaterOrEqual, MustSemanticVersion("1.0.0-rc.1")),
+						}},
+					},
+				},
+			},
+		}
+		projectC = Project{
+			Name: "C",
+			Versions: []ProjectVersion{
+				{Version: MustSemanticVersion("2.0.0-rc.1")},
+				{Version: MustSemanticVersion("1.0.0")},
+			},
+		}
+	)
+
+	ctx := context.Background()
+	db := NewInMemoryDB()
+	require.NoError(t, db.Add(ctx, projectA))
+	require.NoError(t, db.Add(ctx, projectC))
+
+	tests := []struct {
+		name     string
+		rootDeps []Dependency
+		expected []ResolverProjectVersion
+	}{
+		{
+			name:     "origin not selected",
+			rootDeps: []Dependency{{Name: "A"}},
+			expected: []ResolverProjectVersion{
+				{Name: "A", Version: "2.0.0"},
+				{Name: "C", Version: "1.0.0"},
+			},
+		},
+		{
+			name: "origin selected",
+			rootDeps: []Dependency{{Name: "A", Constraints: []VersionConstraint{
+				*NewConstraint(Less, MustSemanticVersion("2.0.0")),
+			}}},
+			expected: []ResolverProjectVersion{
+				{Name: "A", Version: "1.0.0"},
+				{Name: "C", Version: "2.0.0-rc.1"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := NewResolver(db)
+			result, err := r.Resolve(ctx, test.rootDeps)
+			require.NoError(t, err)
+
+			sort.Sort(ResolverProjectVersionByName(result))
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func TestResolver_constraintOriginDiscoveredLater(t *testing.T) {
+	var (
+		projectA = Project{
+			Name: "A",
+			Versions: []ProjectVersion{
+				{
+					Version: MustSemanticVersion("2.0.0"),
+					Dependencies: []Dependency{
+						{Name: "B", Constraints: []VersionConstraint{
+							*NewConstraint(Equal, MustSemanticVersion("1.0.0")),
+						}},
+					},
+				},
+				{Version: MustSemanticVersion("1.0.0")},
+			},
+		}
+		projectB = Project{
+			Name: "B",
+			Versions: []ProjectVersion{
+				{Version: MustSemanticVersion("2.0.0")},
+				{Version: MustSemanticVersion("1.0.0")},
+			},
+		}
+	)
+
+	ctx := context.Background()
+	db := NewInMemoryDB()
+	require.NoError(t, db.Add(ctx, projectA))
+	require.NoError(t, db.Add(ctx, projectB))
+
+	// B is discovered before A, so the constraint from A on B
+	// must not be encoded before A has literals.
+	r := NewResolver(db)
+	result, err := r.Resolve(ctx, []Dependency{
+		{Name: "B"}, {Name: "A"},
+	})
+	require.NoError(t, err)
+
+	sort.Sort(ResolverProjectVersionByName(result))
+	assert.Equal(t, []ResolverProjectVersion{
+		{Name: "A", Version: "1.0.0"},
+		{Name: "B", Version: "2.0.0"},
+	}, result)
+}
+
+func TestResolver_conflicts(t *testing.T) {
+	projectA, err := DefaultVersionSchemes.UnmarshalProject([]byte(`{
+		"name": "A",
+		"versions": [
+			{"version": "2.0.0", "conflicts": [
+				{"name": "B", "constraints": ["<2.0.0"]},
+				{"name": "C"}
+			]},
+			{"version": "1.0.0"}
+		]
+	}`))
+	require.NoError(t, err)
+	projectB := Project{
+		Name: "B",
+		Versions: []ProjectVersion{
+			{Version: MustSemanticVersion("1.0.0")},
+		},
+	}
+
+	ctx := context.Background()
+	db := NewInMemoryDB()
+	require.NoError(t, db.Add(ctx, projectA))
+	require.NoError(t, db.Add(ctx, projectB))
+
+	t.Run("excludes conflicting versions", func(t *testing.T) {
+		r := NewResolver(db)
+		result, err := r.Resolve(ctx, []Dependency{
+			{Name: "A"}, {Name: "B"},
+		})
+		require.NoError(t, err)
+
+		sort.Sort(ResolverProjectVersionByName(result))
+		assert.Equal(t, []ResolverProjectVersion{
+			{Name: "A", Version: "1.0.0"},
+			{Name: "B", Version: "1.0.0"},
+		}, result)
+
+		var conflicts []string
+		for _, c := range r.ConflictsFor(ctx, "B") {
+			conflicts = append(conflicts, c.String())
+		}
+		assert.Equal(t, []string{`A=2.0.0 conflicts with "B" matching <2.0.0`}, conflicts)
+		assert.Empty(t, r.ConstrainsFor(ctx, "B"))
+	})
+
+	t.Run("does not add projects", func(t *testing.T) {
+		r := NewResolver(db)
+		result, err := r.Resolve(ctx, []Dependency{{Name: "A"}})
+		require.NoError(t, err)
+		assert.Equal(t, []ResolverProjectVersion{
+			{Name: "A", Version: "2.0.0"},
+		}, result)
+
+		conflicts := r.ConflictsFor(ctx, "C")
+		require.Len(t, conflicts, 1)
+		assert.Equal(t, `A=2.0.0 conflicts with "C"`, conflicts[0].String())
+	})
+
+	t.Run("unsatisfiable", func(t *testing.T) {
+		r := NewResolver(db)
+		_, err := r.Resolve(ctx, []Dependency{
+			{Name: "A", Constraints: []VersionConstraint{
+				*NewConstraint(Equal, MustSemanticVersion("2.0.0")),
+			}},
+			{Name: "B"},
+		})
+		require.Error(t, err)
+	})
+}
+
+func TestResolver_optionalDependencies(t *testing.T) {
+	projectA, err := DefaultVersionSchemes.UnmarshalProject([]byte(`{
+		"name": "A",
+		"versions": [
+			{"version": "1.0.0", "dependencies": [
+				{"name": "B", "constraints": ["<2.0.0"], "optional": true},
+				{"name": "X", "optional": true}
+			]}
+		]
+	}`))
+	require.NoError(t, err)
+	projectB := Project{
+		Name: "B",
+		Versions: []ProjectVersion{
+			{Version: MustSemanticVersion("2.0.0")},
+			{Version: MustSemanticVersion("1.0.0")},
+		},
+	}
+
+	ctx := context.Background()
+	db := NewInMemoryDB()
+	require.NoError(t, db.Add(ctx, projectA))
+	require.NoError(t, db.Add(ctx, projectB))
+
+	t.Run("not pulled in", func(t *testing.T) {
+		r := NewResolver(db)
+		result, err := r.Resolve(ctx, []Dependency{{Name: "A"}})
+		require.NoError(t, err)
+		assert.Equal(t, []ResolverProjectVersion{
+			{Name: "A", Version: "1.0.0"},
+		}, result)
+	})
+
+	t.Run("constrains when installed", func(t *testing.T) {
+		r := NewResolver(db)
+		result, err := r.Resolve(ctx, []Dependency{{Name: "A"}, {Name: "B"}})
+		require.NoError(t, err)
+
+		sort.Sort(ResolverProjectVersionByName(result))
+		assert.Equal(t, []ResolverProjectVersion{
+			{Name: "A", Version: "1.0.0"},
+			{Name: "B", Version: "1.0.0"},
+		}, result)
+	})
+
+	t.Run("optional root dependency", func(t *testing.T) {
+		r := NewResolver(db)
+		result, err := r.Resolve(ctx, []Dependency{
+			{Name: "B"},
+			{Name: "B", Optional: true, Constraints: []VersionConstraint{
+				*NewConstraint(Less, MustSemanticVersion("2.0.0")),
+			}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []ResolverProjectVersion{
+			{Name: "B", Version: "1.0.0"},
+		}, result)
+	})
+}
+
+func TestResolver_dependencyKinds(t *testing.T) {
+	projectApp, err := DefaultVersionSchemes.UnmarshalProject([]byte(`{
+		"name": "app",
+		"versions": [
+			{"version": "1.0.0", "dependencies": [
+				{"name": "lib"},
+				{"name": "compiler", "kind": "build"},
+				{"name": "testify", "kind": "test"},
+				{"name": "linter", "kind": "dev"},
+				{"name": "framework", "constraints": ["<2.0.0"], "kind": "peer"}
+			]}
+		]
+	}`))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	db := NewInMemoryDB()
+	require.NoError(t, db.Add(ctx, projectApp))
+	for _, name := range []string{"lib", "compiler", "testify", "linter"} {
+		require.NoError(t, db.Add(ctx, Project{
+			Name:     name,
+			Versions: []ProjectVersion{{Version: MustSemanticVersion("1.0.0")}},
+		}))
+	}
+	require.NoError(t, db.Add(ctx, Project{
+		Name: "framework",
+		Versions: []ProjectVersion{
+			{Version: MustSemanticVersion("2.0.0")},
+			{Version: MustSemanticVersion("1.0.0")},
+		},
+	}))
+
+	// resolving the app itself, like a lockfile of its own dependencies.
+	appDeps := projectApp.Versions[0].Dependencies
+
+	tests := []struct {
+		name     string
+		opts     []ResolverOption
+		rootDeps []Dependency
+		expected []string
+	}{
+		{
+			name:     "all kinds",
+			rootDeps: appDeps,
+			expected: []string{"compiler=1.0.0", "lib=1.0.0", "linter=1.0.0", "testify=1.0.0"},
+		},
+		{
+			name:     "runtime",
+			opts:     []ResolverOption{WithDependencyKinds(DependencyRuntime)},
+			rootDeps: appDeps,
+			expected: []string{"lib=1.0.0"},
+		},
+		{
+			name:     "runtime and build",
+			opts:     []ResolverOption{WithDependencyKinds(DependencyRuntime, DependencyBuild)},
+			rootDeps: appDeps,
+			expected: []string{"compiler=1.0.0", "lib=1.0.0"},
+		},
+		{
+			name:     "peer constrains",
+			opts:     []ResolverOption{WithDependencyKinds(DependencyRuntime)},
+			rootDeps: append([]Dependency{{Name: "framework"}}, appDeps...),
+			expected: []string{"framework=1.0.0", "lib=1.0.0"},
+		},
+		{
+			name:     "test and dev dependencies of a dependency",
+			opts:     []ResolverOption{WithDependencyKinds(DependencyRuntime, DependencyBuild, DependencyDev)},
+			rootDeps: []Dependency{{Name: "app"}},
+			expected: []string{"app=1.0.0", "compiler=1.0.0", "lib=1.0.0"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := NewResolver(db, test.opts...)
+			result, err := r.Resolve(ctx, test.rootDeps)
+			require.NoError(t, err)
+
+			var resolved []string
+			for _, rpv := range result {
+				resolved = append(resolved, rpv.String())
+			}
+			sort.Strings(resolved)
+			assert.Equal(t, test.expected, resolved)
+		})
+	}
+}
+
+func TestResolver_environment(t *testing.T) {
+	projectApp, err := DefaultVersionSchemes.UnmarshalProject([]byte(`{
+		"name": "app",
+		"versions": [
+			{"version": "1.0.0", "dependencies": [
+				{"name": "inotify", "marker": "os == \"linux\""},
+				{"name": "fsevents", "marker": "os == \"darwin\""},
+				{"name": "simd", "constraints": [">=2.0.0"], "marker": "arch in [\"amd64\", \"arm64\"]"},
+				{"name": "simd", "constraints": ["<2.0.0"], "marker": "arch not in [\"amd64\", \"arm64\"]"}
+			]}
+		]
+	}`))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	db := NewInMemoryDB()
+	require.NoError(t, db.Add(ctx, projectApp))
+	for _, name := range []string{"inotify", "fsevents"} {
+		require.NoError(t, db.Add(ctx, Project{
+			Name:     name,
+			Versions: []ProjectVersion{{Version: MustSemanticVersion("1.0.0")}},
+		}))
+	}
+	require.NoError(t, db.Add(ctx, Project{
+		Name: "simd",
+		Versions: []ProjectVersion{
+			{Version: MustSemanticVersion("2.0.0")},
+			{Version: MustSemanticVersion("1.0.0")},
+		},
+	}))
+
+	tests := []struct {
+		name     string
+		env      Environment
+		expected []string
+	}{
+		{
+			name:     "linux/amd64",
+			env:      Environment{"os": "linux", "arch": "amd64"},
+			expected: []string{"app=1.0.0", "inotify=1.0.0", "simd=2.0.0"},
+		},
+		{
+			name:     "darwin/arm64",
+			env:      Environment{"os": "darwin", "arch": "arm64"},
+			expected: []string{"app=1.0.0", "fsevents=1.0.0", "simd=2.0.0"},
+		},
+		{
+			name:     "windows/386",
+			env:      Environment{"os": "windows", "arch": "386"},
+			expected: []string{"app=1.0.0", "simd=1.0.0"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := NewResolver(db, WithEnvironment(test.env))
+			result, err := r.Resolve(ctx, []Dependency{{Name: "app"}})
+			require.NoError(t, err)
+
+			var resolved []string
+			for _, rpv := range result {
+				resolved = append(resolved, rpv.String())
+			}
+			sort.Strings(resolved)
+			assert.Equal(t, test.expected, resolved)
+		})
+	}
+
+	t.Run("without environment", func(t *testing.T) {
+		// all markers are ignored, so both simd constraints apply.
+		r := NewResolver(db)
+		_, err := r.Resolve(ctx, []Dependency{{Name: "app"}})
+		require.Error(t, err)
+	})
+}
+
+func TestResolver_environments(t *testing.T) {
+	projectApp, err := DefaultVersionSchemes.UnmarshalProject([]byte(`{
+		"name": "app",
+		"versions": [
+			{"version": "1.0.0", "dependencies": [
+				{"name": "inotify", "marker": "os == \"linux\""},
+				{"name": "fsevents", "marker": "os == \"darwin\""},
+				{"name": "zlib"},
+				{"name": "zlib", "constraints": ["<1.3.0"], "marker": "os == \"darwin\""},
+				{"name": "simd", "constraints": [">=2.0.0"], "marker": "arch in [\"amd64\", \"arm64\"]"},
+				{"name": "simd", "constraints": ["<2.0.0"], "marker": "arch not in [\"amd64\", \"arm64\"]"}
+			]}
+		]
+	}`))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	db := NewInMemoryDB()
+	require.NoError(t, db.Add(ctx, projectApp))
+	for _, name := range []string{"inotify", "fsevents"} {
+		require.NoError(t, db.Add(ctx, Project{
+			Name:     name,
+			Versions: []ProjectVersion{{Version: MustSemanticVersion("1.0.0")}},
+		}))
+	}
+	require.NoError(t, db.Add(ctx, Project{
+		Name: "zlib",
+		Versions: []ProjectVersion{
+			{Version: MustSemanticVersion("1.3.0")},
+			{Version: MustSemanticVersion("1.2.0")},
+		},
+	}))
+	require.NoError(t, db.Add(ctx, Project{
+		Name: "simd",
+		Versions: []ProjectVersion{
+			{Version: MustSemanticVersion("2.0.0")},
+			{Version: MustSemanticVersion("1.0.0")},
+		},
+	}))
+
+	var (
+		linuxAMD64  = Environment{"os": "linux", "arch": "amd64"}
+		linuxARM64  = Environment{"os": "linux", "arch": "arm64"}
+		darwinARM64 = Environment{"os": "darwin", "arch": "arm64"}
+		windows386  = Environment{"os": "windows", "arch": "386"}
+	)
+
+	t.Run("linux", func(t *testing.T) {
+		r := NewResolver(db, WithEnvironments(linuxAMD64, linuxARM64))
+		result, err := r.Resolve(ctx, []Dependency{{Name: "app"}})
+		require.NoError(t, err)
+
+		sort.Sort(ResolverProjectVersionByName(result))
+		assert.Equal(t, []ResolverProjectVersion{
+			{Name: "app", Version: "1.0.0"},
+			{Name: "inotify", Version: "1.0.0"},
+			{Name: "simd", Version: "2.0.0"},
+			{Name: "zlib", Version: "1.3.0"},
+		}, result)
+		assert.Equal(t, []Environment{linuxAMD64, linuxARM64}, r.EnvironmentsFor(ctx, "inotify"))
+		assert.Empty(t, r.EnvironmentsFor(ctx, "fsevents"))
+	})
+
+	t.Run("universal", func(t *testing.T) {
+		r := NewResolver(db, WithEnvironments(linuxAMD64, linuxARM64, darwinARM64))
+		result, err := r.Resolve(ctx, []Dependency{{Name: "app"}})
+		require.NoError(t, err)
+
+		sort.Sort(ResolverProjectVersionByName(result))
+		assert.Equal(t, []ResolverProjectVersion{
+			{Name: "app", Version: "1.0.0"},
+			{Name: "fsevents", Version: "1.0.0"},
+			{Name: "inotify", Version: "1.0.0"},
+			{Name: "simd", Version: "2.0.0"},
+			// the darwin constraint applies to all environments sharing the lock.
+			{Name: "zlib", Version: "1.2.0"},
+		}, result)
+		assert.Equal(t, []Environment{linuxAMD64, linuxARM64}, r.EnvironmentsFor(ctx, "inotify"))
+		assert.Equal(t, []Environment{darwinARM64}, r.EnvironmentsFor(ctx, "fsevents"))
+		assert.Equal(t, []Environment{linuxAMD64, linuxARM64, darwinARM64}, r.EnvironmentsFor(ctx, "zlib"))
+	})
+
+	t.Run("no shared version", func(t *testing.T) {
+		r := NewResolver(db, WithEnvironments(linuxAMD64, windows386))
+		_, err := r.Resolve(ctx, []Dependency{{Name: "app"}})
+		require.Error(t, err)
+	})
+}
+
+func TestResolver_environmentsConflicts(t *testing.T) {
+	ctx := context.Background()
+	db := NewInMemoryDB()
+	require.NoError(t, db.Add(ctx, Project{Name: "app", Versions: []ProjectVersion{{
+		Version: MustSemanticVersion("1.0.0"),
+		Dependencies: []Dependency{
+			{Name: "mta"},
+			{Name: "postfix", Marker: MustMarker(`os == "linux"`)},
+			{Name: "sendmail", Marker: MustMarker(`os == "darwin"`)},
+		},
+	}}}))
+	require.NoError(t, db.Add(ctx, Project{Name: "postfix", Versions: []ProjectVersion{{
+		Version:   MustSemanticVersion("1.0.0"),
+		Provides:  []Capability{{Name: "mta"}},
+		Conflicts: []Dependency{{Name: "sendmail"}},
+	}}}))
+	require.NoError(t, db.Add(ctx, Project{Name: "sendmail", Versions: []ProjectVersion{{
+		Version:  MustSemanticVersion("1.0.0"),
+		Provides: []Capability{{Name: "mta"}},
+	}}}))
+
+	linux, darwin := Environment{"os": "linux"}, Environment{"os": "darwin"}
+
+	// conflicts and capabilities apply within each environment.
+	r := NewResolver(db, WithEnvironments(linux, darwin))
+	result, err := r.Resolve(ctx, []Dependency{{Name: "app"}})
+	require.NoError(t, err)
+
+	sort.Sort(ResolverProjectVersionByName(result))
+	assert.Equal(t, []ResolverProjectVersion{
+		{Name: "app", Version: "1.0.0"},
+		{Name: "postfix", Version: "1.0.0"},
+		{Name: "sendmail", Version: "1.0.0"},
+	}, result)
+	assert.Equal(t, []Environment{linux}, r.EnvironmentsFor(ctx, "postfix"))
+	assert.Equal(t, []Environment{darwin}, r.EnvironmentsFor(ctx, "sendmail"))
+
+	// without environments, postfix and sendmail are installed together.
+	r = NewResolver(db)
+	_, err = r.Resolve(ctx, []Dependency{{Name: "app"}})
+	require.Error(t, err)
+}
+
+func TestResolver_uniquenessKey(t *testing.T) {
+	var (
+		projectApp = Project{
+			Name: "app",
+			Versions: []ProjectVersion{
+				{
+					Version: MustSemanticVersion("1.0.0"),
+					Dependencies: []Dependency{
+						{Name: "legacy"},
+						{Name: "lodash", Constraints: []VersionConstraint{
+							*NewConstraint(GreaterOrEqual, MustSemanticVersion("4.0.0")),
+						}},
+					},
+				},
+			},
+		}
+		projectLegacy = Project{
+			Name: "legacy",
+			Versions: []ProjectVersion{
+				{
+					Version: MustSemanticVersion("1.0.0"),
+					Dependencies: []Dependency{
+						{Name: "lodash", Constraints: []VersionConstraint{
+							*NewConstraint(Less, MustSemanticVersion("4.0.0")),
+						}},
+					},
+				},
+			},
+		}
+		projectLodash = Project{
+			Name: "lodash",
+			Versions: []ProjectVersion{
+				{Version: MustSemanticVersion("4.17.0")},
+				{Version: MustSemanticVersion("4.1.0")},
+				{Version: MustSemanticVersion("3.10.0")},
+				{Version: MustSemanticVersion("3.0.0")},
+			},
+		}
+	)
+
+	ctx := context.Background()
+	db := NewInMemoryDB()
+	require.NoError(t, db.Add(ctx, projectApp))
+	require.NoError(t, db.Add(ctx, projectLegacy))
+	require.NoError(t, db.Add(ctx, projectLodash))
+
+	t.Run("side by side", func(t *testing.T) {
+		r := NewResolver(db, WithUniquenessKey(MajorVersionKey, "lodash"))
+		result, err := r.Resolve(ctx, []Dependency{{Name: "app"}})
+		require.NoError(t, err)
+
+		sort.Slice(result, func(i, j int) bool { return result[i].String() < result[j].String() })
+		assert.Equal(t, []ResolverProjectVersion{
+			{Name: "app", Version: "1.0.0"},
+			{Name: "legacy", Version: "1.0.0"},
+			{Name: "lodash", Version: "3.10.0"},
+			{Name: "lodash", Version: "4.17.0"},
+		}, result)
+
+		var links []string
+		for _, link := range r.Links(ctx) {
+			links = append(links, link.String())
+		}
+		assert.Equal(t, []string{
+			"app=1.0.0 -> legacy=1.0.0",
+			"app=1.0.0 -> lodash=4.17.0",
+			"legacy=1.0.0 -> lodash=3.10.0",
+			"root -> app=1.0.0",
+		}, links)
+	})
+
+	t.Run("single copy", func(t *testing.T) {
+		r := NewResolver(db, WithUniquenessKey(MajorVersionKey, "lodash"))
+		result, err := r.Resolve(ctx, []Dependency{{Name: "lodash"}})
+		require.NoError(t, err)
+		assert.Equal(t, []ResolverProjectVersion{
+			{Name: "lodash", Version: "4.17.0"},
+		}, result)
+	})
+
+	t.Run("same key", func(t *testing.T) {
+		r := NewResolver(db, WithUniquenessKey(MajorVersionKey, "lodash"))
+		_, err := r.Resolve(ctx, []Dependency{
+			{Name: "lodash", Constraints: []VersionConstraint{
+				*NewConstraint(Equal, MustSemanticVersion("4.1.0")),
+			}},
+			{Name: "lodash", Constraints: []VersionConstraint{
+				*NewConstraint(Equal, MustSemanticVersion("4.17.0")),
+			}},
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("without key", func(t *testing.T) {
+		r := NewResolver(db)
+		_, err := r.Resolve(ctx, []Dependency{{Name: "app"}})
+		require.Error(t, err)
+	})
+}
+
+func TestResolver_versionStatus(t *testing.T) {
+	projectLib, err := DefaultVersionSchemes.UnmarshalProject([]byte(`{
+		"name": "lib",
+		"versions": [
+			{"version": "1.3.0", "yanked": true},
+			{"version": "1.2.0", "deprecated": "use 2.x"},
+			{"version": "1.1.0"},
+			{"version": "1.0.0", "retracted": "broken build"}
+		]
+	}`))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	db := NewInMemoryDB()
+	require.NoError(t, db.Add(ctx, projectLib))
+
+	npmPin, err := ParseNPMRange("1.3.0")
+	require.NoError(t, err)
+	cargoPin, err := ParseCargoRequirement("=1.3.0")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		opts        []ResolverOption
+		constraints []VersionConstraint
+		expected    string
+		warnings    []string
+	}{
+		{
+			name:     "latest",
+			expected: "1.1.0",
+		},
+		{
+			name:        "pinned yanked",
+			constraints: []VersionConstraint{*NewConstraint(Equal, MustSemanticVersion("1.3.0"))},
+			expected:    "1.3.0",
+			warnings:    []string{"lib=1.3.0: yanked"},
+		},
+		{
+			name:        "pinned yanked npm",
+			constraints: []VersionConstraint{npmPin},
+			expected:    "1.3.0",
+			warnings:    []string{"lib=1.3.0: yanked"},
+		},
+		{
+			name:        "pinned yanked cargo",
+			constraints: []VersionConstraint{cargoPin},
+			expected:    "1.3.0",
+			warnings:    []string{"lib=1.3.0: yanked"},
+		},
+		{
+			name:        "yanked not pinned",
+			constraints: []VersionConstraint{*NewConstraint(Greater, MustSemanticVersion("1.2.0"))},
+		},
+		{
+			name: "locked yanked",
+			opts: []ResolverOption{
+				WithLockedVersions(ResolverProjectVersion{Name: "lib", Version: "1.3.0"}),
+			},
+			expected: "1.3.0",
+			warnings: []string{"lib=1.3.0: yanked"},
+		},
+		{
+			name:        "deprecated",
+			constraints: []VersionConstraint{*NewConstraint(GreaterOrEqual, MustSemanticVersion("1.2.0"))},
+			expected:    "1.2.0",
+			warnings:    []string{"lib=1.2.0: deprecated: use 2.x"},
+		},
+		{
+			name:        "retracted",
+			constraints: []VersionConstraint{*NewConstraint(Less, MustSemanticVersion("1.1.0"))},
+			expected:    "1.0.0",
+			warnings:    []string{"lib=1.0.0: retracted: broken build"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := NewResolver(db, test.opts...)
+			result, err := r.Resolve(ctx, []Dependency{{Name: "lib", Constraints: test.constraints}})
+			if len(test.expected) == 0 {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []ResolverProjectVersion{{Name: "lib", Version: test.expected}}, result)
+
+			var warnings []string
+			for _, w := range r.Warnings(ctx) {
+				warnings = append(warnings, w.String())
+			}
+			assert.Equal(t, test.warnings, warnings)
+		})
+	}
+}
+
+func TestResolver_yankedPinnedByUnselectedOrigin(t *testing.T) {
+	var (
+		projectA = Project{
+			Name: "A",
+			Versions: []ProjectVersion{
+				{
+					Version: MustSemanticVersion("2.0.0"),
+					Dependencies: []Dependency{
+						{Name: "C", Constraints: []VersionConstraint{
+							*NewConstraint(GreaterOrEqual, MustSemanticVersion("1.0.0")),
+						}},
+					},
+				},
+				{
+					Version: MustSemanticVersion("1.0.0"),
+					Dependencies: []Dependency{
+						{Name: "C", Constraints: []VersionConstraint{
+							*NewConstraint(Equal, MustSemanticVersion("1.5.0")),
+						}},
+					},
+				},
+			},
+		}
+		projectC = Project{
+			Name: "C",
+			Versions: []ProjectVersion{
+				{Version: MustSemanticVersion("1.5.0"), Yanked: true},
+				{Version: MustSemanticVersion("1.0.0")},
+			},
+		}
+	)
+
+	ctx := context.Background()
+	db := NewInMemoryDB()
+	require.NoError(t, db.Add(ctx, projectA))
+	require.NoError(t, db.Add(ctx, projectC))
+
+	tests := []struct {
+		name     string
+		rootDeps []Dependency
+		opts     []ResolverOption
+		expected []ResolverProjectVersion
+		warnings []string
+	}{
+		{
+			name:     "origin not selected",
+			rootDeps: []Dependency{{Name: "A"}},
+			expected: []ResolverProjectVersion{
+				{Name: "A", Version: "2.0.0"},
+				{Name: "C", Version: "1.0.0"},
+			},
+		},
+		{
+			name: "origin selected",
+			rootDeps: []Dependency{{Name: "A", Constraints: []VersionConstraint{
+				*NewConstraint(Less, MustSemanticVersion("2.0.0")),
+			}}},
+			expected: []ResolverProjectVersion{
+				{Name: "A", Version: "1.0.0"},
+				{Name: "C", Version: "1.5.0"},
+			},
+			warnings: []string{"C=1.5.0: yanked"},
+		},
+		{
+			name:     "locked",
+			rootDeps: []Dependency{{Name: "A"}},
+			opts: []ResolverOption{
+				WithLockedVersions(ResolverProjectVersion{Name: "C", Version: "1.5.0"}),
+			},
+			expected: []ResolverProjectVersion{
+				{Name: "A", Version: "2.0.0"},
+				{Name: "C", Version: "1.5.0"},
+			},
+			warnings: []string{"C=1.5.0: yanked"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := NewResolver(db, test.opts...)
+			result, err := r.Resolve(ctx, test.rootDeps)
+			require.NoError(t, err)
+
+			sort.Sort(ResolverProjectVersionByName(result))
+			assert.Equal(t, test.expected, result)
+
+			var warnings []string
+			for _, w := range r.Warnings(ctx) {
+				warnings = append(warnings, w.String())
+			}
+			assert.Equal(t, test.warnings, warnings)
+		})
+	}
+}
+
+func TestResolver_overrides(t *testing.T) {
+	var (
+		projectA = Project{
+			Name: "A",
+			Versions: []ProjectVersion{
+				{
+					Version: MustSemanticVersion("1.0.0"),
+					Dependencies: []Dependency{
+						{Name: "B"},
+						{Name: "C", Constraints: []VersionConstraint{
+							*NewConstraint(Less, MustSemanticVersion("2.0.0")),
+						}},
+					},
+				},
+			},
+		}
+		projectB = Project{
+			Name: "B",
+			Versions: []ProjectVersion{
+				{Version: MustSemanticVersion("1.3.0")},
+				{Version: MustSemanticVersion("1.2.0")},
+			},
+		}
+		projectC = Project{
+			Name: "C",
+			Versions: []ProjectVersion{
+				{Version: MustSemanticVersion("2.0.1")},
+				{Version: MustSemanticVersion("2.0.0")},
+				{Version: MustSemanticVersion("1.0.0")},
+			},
+		}
+	)
+
+	ctx := context.Background()
+	db := NewInMemoryDB()
+	require.NoError(t, db.Add(ctx, projectA))
+	require.NoError(t, db.Add(ctx, projectB))
+	require.NoError(t, db.Add(ctx, projectC))
+
+	tests := []struct {
+		name      string
+		overrides Overrides
+		expected  []string
+		applied   []string
+		links     []string
+	}{
+		{
+			name:     "none",
+			expected: []string{"A=1.0.0", "B=1.3.0", "C=1.0.0"},
+			links:    []string{"A=1.0.0 -> B=1.3.0", "A=1.0.0 -> C=1.0.0", "root -> A=1.0.0"},
+		},
+		{
+			name: "ignore constraint",
+			overrides: Overrides{
+				Ignore: []IgnoredConstraint{{Origin: "A", Subject: "C"}},
+			},
+			expected: []string{"A=1.0.0", "B=1.3.0", "C=2.0.1"},
+			applied:  []string{`ignored A=1.0.0 constrains "C" with <2.0.0`},
+			links:    []string{"A=1.0.0 -> B=1.3.0", "A=1.0.0 -> C=2.0.1", "root -> A=1.0.0"},
+		},
+		{
+			name: "force version",
+			overrides: Overrides{
+				Force: []Dependency{{Name: "C", Constraints: []VersionConstraint{
+					*NewConstraint(Equal, MustSemanticVersion("2.0.0")),
+				}}},
+			},
+			expected: []string{"A=1.0.0", "B=1.3.0", "C=2.0.0"},
+			applied: []string{
+				`forced "C" to =2.0.0`,
+				`forced "C" to =2.0.0, replacing A=1.0.0 constrains "C" with <2.0.0`,
+			},
+			links: []string{"A=1.0.0 -> B=1.3.0", "A=1.0.0 -> C=2.0.0", "root -> A=1.0.0"},
+		},
+		{
+			name: "force version without competing constraint",
+			overrides: Overrides{
+				Force: []Dependency{{Name: "B", Constraints: []VersionConstraint{
+					*NewConstraint(Equal, MustSemanticVersion("1.2.0")),
+				}}},
+			},
+			expected: []string{"A=1.0.0", "B=1.2.0", "C=1.0.0"},
+			applied:  []string{`forced "B" to =1.2.0`},
+			links:    []string{"A=1.0.0 -> B=1.2.0", "A=1.0.0 -> C=1.0.0", "root -> A=1.0.0"},
+		},
+		{
+			name: "exclude version",
+			overrides: Overrides{
+				Exclude: []ResolverProjectVersion{{Name: "B", Version: "1.3.0"}},
+			},
+			expected: []string{"A=1.0.0", "B=1.2.0", "C=1.0.0"},
+			applied:  []string{"excluded B=1.3.0"},
+			links:    []string{"A=1.0.0 -> B=1.2.0", "A=1.0.0 -> C=1.0.0", "root -> A=1.0.0"},
+		},
+		{
+			name: "exclude unknown version",
+			overrides: Overrides{
+				Exclude: []ResolverProjectVersion{{Name: "B", Version: "9.9.9"}},
+			},
+			expected: []string{"A=1.0.0", "B=1.3.0", "C=1.0.0"},
+			links:    []string{"A=1.0.0 -> B=1.3.0", "A=1.0.0 -> C=1.0.0", "root -> A=1.0.0"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := NewResolver(db, WithOverrides(test.overrides))
+			result, err := r.Resolve(ctx, []Dependency{{Name: "A"}})
+			require.NoError(t, err)
+
+			var resolved []string
+			for _, rpv := range result {
+				resolved = append(resolved, rpv.String())
+			}
+			sort.Strings(resolved)
+			assert.Equal(t, test.expected, resolved)
+
+			var applied []string
+			for _, override := range r.AppliedOverrides(ctx) {
+				applied = append(applied, override.String())
+			}
+			assert.Equal(t, test.applied, applied)
+
+			var links []string
+			for _, link := range r.Links(ctx) {
+				links = append(links, link.String())
+			}
+			assert.Equal(t, test.links, links)
+		})
+	}
+}
+
+func TestResolver_absentProjects(t *testing.T) {
+	var (
+		projectA = Project{
+			Name: "A",
+			Versions: []ProjectVersion{
+				{
+					Version: MustSemanticVersion("2.0.0"),
+					Dependencies: []Dependency{
+						{Name: "B"},
+					},
+				},
+				{
+					Version: MustSemanticVersion("1.0.0"),
+					Dependencies: []Dependency{
+						{Name: "D"},
+					},
+				},
+			},
+		}
+		projectB = Project{
+			Name: "B",
+			Versions: []ProjectVersion{
+				{
+					Version: MustSemanticVersion("1.0.0"),
+					Dependencies: []Dependency{
+						// cycle
+						{Name: "A"},
+					},
+				},
+			},
+		}
+		projectD = Project{
+			Name: "D",
+			Versions: []ProjectVersion{
+				{Version: MustSemanticVersion("1.0.0")},
+			},
+		}
+	)
+
+	ctx := context.Background()
+	db := NewInMemoryDB()
+	require.NoError(t, db.Add(ctx, projectA))
+	require.NoError(t, db.Add(ctx, projectB))
+	require.NoError(t, db.Add(ctx, projectD))
+
+	tests := []struct {
+		name     string
+		rootDeps []Dependency
+		expected []ResolverProjectVersion
+	}{
+		{
+			name:     "unneeded dependency of old version",
+			rootDeps: []Dependency{{Name: "A"}},
+			expected: []ResolverProjectVersion{
+				{Name: "A", Version: "2.0.0"},
+				{Name: "B", Version: "1.0.0"},
+			},
+		},
+		{
+			name: "needed dependency of old version",
+			rootDeps: []Dependency{{Name: "A", Constraints: []VersionConstraint{
+				*NewConstraint(Less, MustSemanticVersion("2.0.0")),
+			}}},
+			expected: []ResolverProjectVersion{
+				{Name: "A", Version: "1.0.0"},
+				{Name: "D", Version: "1.0.0"},
+			},
+		},
+		{
+			name:     "only optional dependencies",
+			rootDeps: []Dependency{{Name: "A", Optional: true}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := NewResolver(db)
+			result, err := r.Resolve(ctx, test.rootDeps)
+			require.NoError(t, err)
+
+			sort.Sort(ResolverProjectVersionByName(result))
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func TestResolver_provides(t *testing.T) {
+	var (
+		projectApp = Project{
+			Name: "app",
+			Versions: []ProjectVersion{
+				{
+					Version: MustSemanticVersion("1.0.0"),
+					Dependencies: []Dependency{
+						{Name: "logging-backend"},
+					},
+				},
+			},
+		}
+		projectLogrus = Project{
+			Name: "logrus",
+			Versions: []ProjectVersion{
+				{
+					Version: MustSemanticVersion("2.0.0"),
+					Provides: []Capability{
+						{Name: "logging-backend", Version: MustSemanticVersion("1.1.0")},
+					},
+				},
+				{
+					Version: MustSemanticVersion("1.0.0"),
+					Provides: []Capability{
+						{Name: "logging-backend", Version: MustSemanticVersion("1.0.0")},
+					},
+				},
+			},
+		}
+		projectZap = Project{
+			Name: "zap",
+			Versions: []ProjectVersion{
+				{
+					Version: MustSemanticVersion("1.0.0"),
+					Provides: []Capability{
+						{Name: "logging-backend", Version: MustSemanticVersion("2.0.0")},
+					},
+				},
+			},
+		}
+		projectNop = Project{
+			Name: "nop",
+			Versions: []ProjectVersion{
+				{
+					Version: MustSemanticVersion("1.0.0"),
+					Provides: []Capability{
+						{Name: "logging-backend"},
+					},
+				},
+			},
+		}
+	)
+
+	ctx := context.Background()
+	db := NewInMemoryDB()
+	for _, p := range []Project{projectApp, projectLogrus, projectZap, projectNop} {
+		require.NoError(t, db.Add(ctx, p))
+	}
+
+	providers, err := db.Providers(ctx, "logging-backend")
+	require.NoError(t, err)
+	var providerNames []string
+	for _, p := range providers {
+		providerNames = append(providerNames, p.Name)
+	}
+	assert.Equal(t, []string{"logrus", "nop", "zap"}, providerNames)
+
+	tests := []struct {
+		name     string
+		rootDeps []Dependency
+		opts     []ResolverOption
+		expected []ResolverProjectVersion
+	}{
+		{
+			name:     "first provider by name",
+			rootDeps: []Dependency{{Name: "app"}},
+			expected: []ResolverProjectVersion{
+				{Name: "app", Version: "1.0.0"},
+				{Name: "logrus", Version: "2.0.0"},
+			},
+		},
+		{
+			name:     "provider priority",
+			rootDeps: []Dependency{{Name: "app"}},
+			opts: []ResolverOption{
+				WithProviderPriority("logging-backend", "nop", "zap"),
+			},
+			expected: []ResolverProjectVersion{
+				{Name: "app", Version: "1.0.0"},
+				{Name: "nop", Version: "1.0.0"},
+			},
+		},
+		{
+			name: "constrained capability version",
+			rootDeps: []Dependency{
+				{Name: "app"},
+				{Name: "logging-backend", Constraints: []VersionConstraint{
+					*NewConstraint(Less, MustSemanticVersion("1.1.0")),
+				}},
+			},
+			opts: []ResolverOption{
+				WithProviderPriority("logging-backend", "nop", "logrus"),
+			},
+			expected: []ResolverProjectVersion{
+				{Name: "app", Version: "1.0.0"},
+				{Name: "logrus", Version: "1.0.0"},
+			},
+		},
+		{
+			name: "concrete dependency on provider",
+			rootDeps: []Dependency{
+				{Name: "app"},
+				{Name: "zap"},
+			},
+			expected: []ResolverProjectVersion{
+				{Name: "app", Version: "1.0.0"},
+				{Name: "zap", Version: "1.0.0"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := NewResolver(db, test.opts...)
+			result, err := r.Resolve(ctx, test.rootDeps)
+			require.NoError(t, err)
+
+			sort.Sort(ResolverProjectVersionByName(result))
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func TestResolver_replaces(t *testing.T) {
+	dependsOnLogrus := func(name string, c VersionConstraint) Project {
+		return Project{
+			Name: name,
+			Versions: []ProjectVersion{
+				{
+					Version: MustSemanticVersion("1.0.0"),
+					Dependencies: []Dependency{
+						{Name: "logrus", Constraints: []VersionConstraint{c}},
+					},
+				},
+			},
+		}
+	}
+	var (
+		projectApp    = dependsOnLogrus("app", *NewConstraint(GreaterOrEqual, MustSemanticVersion("1.8.0")))
+		projectLegacy = dependsOnLogrus("legacy", *NewConstraint(Less, MustSemanticVersion("1.9.1")))
+		projectLogrus = Project{
+			Name: "logrus",
+			Versions: []ProjectVersion{
+				{Version: MustSemanticVersion("1.9.0")},
+			},
+		}
+		projectLogCore = Project{
+			Name: "log-core",
+			Versions: []ProjectVersion{
+				{
+					Version: MustSemanticVersion("2.0.0"),
+					Replaces: []Capability{
+						{Name: "logrus", Version: MustSemanticVersion("1.9.3")},
+						{Name: "logrus-hooks"},
+					},
+				},
+			},
+		}
+		projectHooksUser = Project{
+			Name: "hooks-user",
+			Versions: []ProjectVersion{
+				{
+					Version: MustSemanticVersion("1.0.0"),
+					Dependencies: []Dependency{
+						{Name: "logrus-hooks"},
+					},
+				},
+			},
+		}
+	)
+
+	ctx := context.Background()
+	db := NewInMemoryDB()
+	for _, p := range []Project{projectApp, projectLegacy, projectLogrus, projectLogCore, projectHooksUser} {
+		require.NoError(t, db.Add(ctx, p))
+	}
+
+	tests := []struct {
+		name          string
+		rootDeps      []Dependency
+		expected      []ResolverProjectVersion
+		substitutions []string
+	}{
+		{
+			name:          "replacement preferred",
+			rootDeps:      []Dependency{{Name: "app"}},
+			expected:      []ResolverProjectVersion{{Name: "app", Version: "1.0.0"}, {Name: "log-core", Version: "2.0.0"}},
+			substitutions: []string{`log-core=2.0.0 replaces "logrus"`},
+		},
+		{
+			name:     "replacement does not satisfy constraint",
+			rootDeps: []Dependency{{Name: "legacy"}},
+			expected: []ResolverProjectVersion{{Name: "legacy", Version: "1.0.0"}, {Name: "logrus", Version: "1.9.0"}},
+		},
+		{
+			name:          "replaced project no longer exists",
+			rootDeps:      []Dependency{{Name: "hooks-user"}},
+			expected:      []ResolverProjectVersion{{Name: "hooks-user", Version: "1.0.0"}, {Name: "log-core", Version: "2.0.0"}},
+			substitutions: []string{`log-core=2.0.0 replaces "logrus-hooks"`},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := NewResolver(db)
+			result, err := r.Resolve(ctx, test.rootDeps)
+			require.NoError(t, err)
+
+			sort.Sort(ResolverProjectVersionByName(result))
+			assert.Equal(t, test.expected, result)
+
+			var substitutions []string
+			for _, s := range r.Substitutions(ctx) {
+				substitutions = append(substitutions, s.String())
+			}
+			assert.Equal(t, test.substitutions, substitutions)
+		})
+	}
+
+	t.Run("mutually exclusive", func(t *testing.T) {
+		r := NewResolver(db)
+		_, err := r.Resolve(ctx, []Dependency{{Name: "legacy"}, {Name: "log-core"}})
+		require.Error(t, err)
+
+		conflicts := r.ConflictsFor(ctx, "logrus")
+		require.Len(t, conflicts, 1)
+		assert.Equal(t, `log-core=2.0.0 conflicts with "logrus"`, conflicts[0].String())
+	})
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RPM package version: [epoch:]version[-release].
+type RPMVersion struct {
+	epoch   int
+	version string
+	release string
+}
+
+var (
+	_ Version = (*RPMVersion)(nil)
+)
+
+var RPMVersionScheme = VersionScheme{
+	Name:  "rpm",
+	Parse: ParseRPMVersion,
+}
+
+func MustRPMVersion(v string) *RPMVersion {
+	rv, err := NewRPMVersion(v)
+	if err != nil {
+		panic(err)
+	}
+	return rv
+}
+
+func NewRPMVersion(v string) (*RPMVersion, error) {
+	rv := &RPMVersion{}
+	rest := strings.TrimSpace(v)
+
+	if i := strings.IndexByte(rest, ':'); i != -1 {
+		epoch, err := strconv.Atoi(rest[:i])
+		if err != nil || epoch < 0 {
+			return nil, fmt.Errorf("invalid rpm version %q: epoch is not a number", v)
+		}
+		rv.epoch = epoch
+		rest = rest[i+1:]
+	}
+	if i := strings.LastIndexByte(rest, '-'); i != -1 {
+		rv.release = rest[i+1:]
+		rest = rest[:i]
+		if len(rv.release) == 0 {
+			return nil, fmt.Errorf("invalid rpm version %q: empty release", v)
+		}
+	}
+	rv.version = rest
+
+	if len(rv.version) == 0 {
+		return nil, fmt.Errorf("invalid rpm version %q: empty version", v)
+	}
+	if strings.ContainsAny(rv.version, "-:") {
+		return nil, fmt.Errorf("invalid rpm version %q: version must not contain '-' or ':'", v)
+	}
+	return rv, nil
+}
+
+func ParseRPMVersion(v string) (Version, error) {
+	return NewRPMVersion(v)
+}
+
+func (rv *RPMVersion) Equal(v Version) bool {
+	otherRV, ok := v.(*RPMVersion)
+	if !ok || otherRV == nil {
+		return false
+	}
+	return rv.compare(otherRV) == 0
+}
+
+func (rv *RPMVersion) Less(v Version) bool {
+	otherRV, ok := v.(*RPMVersion)
+	if !ok || otherRV == nil {
+		return false
+	}
+	return rv.compare(otherRV) < 0
+}
+
+func (rv *RPMVersion) String() string {
+	s := rv.version
+	if rv.epoch != 0 {
+		s = strconv.Itoa(rv.epoch) + ":" + s
+	}
+	if len(rv.release) != 0 {
+		s += "-" + rv.release
+	}
+	return s
+}
+
+func (rv *RPMVersion) Scheme() string {
+	return RPMVersionScheme.Name
+}
+
+// Compares epoch, version and release in that order.
+// A missing release sorts before any release.
+func (rv *RPMVersion) compare(other *RPMVersion) int {
+	if c := compareInt(rv.epoch, other.epoch); c != 0 {
+		return c
+	}
+	if c := rpmVerCmp(rv.version, other.version); c != 0 {
+		return c
+	}
+	return rpmVerCmp(rv.release, other.release)
+}
+
+// Port of rpmvercmp from rpm.
+// Compares alternating alphabetic and numeric segments,
+// numeric segments are newer than alphabetic ones.
+// "~" sorts before everything and "^" after the end of the string.
+func rpmVerCmp(a, b string) int {
+	if a == b {
+		return 0
+	}
+
+	isSep := func(c byte) bool {
+		return !isASCIIAlnum(rune(c)) && c != '~' && c != '^'
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		for i < len(a) && isSep(a[i]) {
+			i++
+		}
+		for j < len(b) && isSep(b[j]) {
+			j++
+		}
+
+		// tilde separator sorts before everything else
+		if (i < len(a) && a[i] == '~') || (j < len(b) && b[j] == '~') {
+			if i >= len(a) || a[i] != '~' {
+				return 1
+			}
+			if j >= len(b) || b[j] != '~' {
+				return -1
+			}
+			i++
+			j++
+			continue
+		}
+
+		// caret separator sorts after the end of the string,
+		// but before everything else.
+		if (i < len(a) && a[i] == '^') || (j < len(b) && b[j] == '^') {
+			if i >= len(a) {
+				return -1
+			}
+			if j >= len(b) {
+				return 1
+			}
+			if a[i] != '^' {
+				return 1
+			}
+			if b[j] != '^' {
+				return -1
+			}
+			i++
+			j++
+			continue
+		}
+
+		if i >= len(a) || j >= len(b) {
+			break
+		}
+
+		// grab the next segment of the same type from both strings
+		segEnd := func(s string, start int, numeric bool) int {
+			k := start
+			for k < len(s) {
+				if numeric && !isASCIIDigit(s[k]) || !numeric && !isASCIIAlpha(s[k]) {
+					break
+				}
+				k++
+			}
+			return k
+		}
+		numeric := isASCIIDigit(a[i])
+		iEnd, jEnd := segEnd(a, i, numeric), segEnd(b, j, numeric)
+
+		// segments of different types, numeric ones are newer
+		if j == jEnd {
+			if numeric {
+				return 1
+			}
+			return -1
+		}
+
+		segA, segB := a[i:iEnd], b[j:jEnd]
+		if numeric {
+			segA = strings.TrimLeft(segA, "0")
+			segB = strings.TrimLeft(segB, "0")
+			if c := compareInt(len(segA), len(segB)); c != 0 {
+				return c
+			}
+		}
+		if c := strings.Compare(segA, segB); c != 0 {
+			return c
+		}
+		i, j = iEnd, jEnd
+	}
+
+	switch {
+	case i >= len(a) && j >= len(b):
+		return 0
+	case i < len(a):
+		return 1
+	default:
+		return -1
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRPMVersion(t *testing.T) {
+	rv, err := NewRPMVersion("1:2.34-5.el9")
+	require.NoError(t, err)
+
+	assert.Equal(t, "1:2.34-5.el9", rv.String())
+	assert.Equal(t, "rpm", rv.Scheme())
+
+	assert.False(t, rv.Equal(nil))
+	assert.True(t, rv.Equal(rv))
+	assert.False(t, rv.Equal(MustRPMVersion("2.34-5.el9")))
+
+	assert.False(t, rv.Less(nil))
+	assert.False(t, rv.Less(MustRPMVersion("1:2.34-5")))
+	assert.True(t, rv.Less(MustRPMVersion("1:2.34-6.el9")))
+
+	assert.Panics(t, func() {
+		MustRPMVersion("")
+	})
+}
+
+func TestNewRPMVersion_invalid(t *testing.T) {
+	tests := map[string]string{
+		"":       `invalid rpm version "": empty version`,
+		"x:1.0":  `invalid rpm version "x:1.0": epoch is not a number`,
+		"1.0-":   `invalid rpm version "1.0-": empty release`,
+		"1:2:3":  `invalid rpm version "1:2:3": version must not contain '-' or ':'`,
+		"-1.el9": `invalid rpm version "-1.el9": empty version`,
+	}
+	for v, expectedErr := range tests {
+		t.Run(v, func(t *testing.T) {
+			_, err := NewRPMVersion(v)
+			require.EqualError(t, err, expectedErr)
+		})
+	}
+}
+
+func TestRPMVerCmp(t *testing.T) {
+	// Comparison vectors from rpm's tests/rpmvercmp.at.
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.0", "1.0", 0},
+		{"1.0", "2.0", -1},
+		{"2.0.1", "2.0.1", 0},
+		{"2.0", "2.0.1", -1},
+		{"2.0.1a", "2.0.1a", 0},
+		{"2.0.1a", "2.0.1", 1},
+		{"5.5p1", "5.5p1", 0},
+		{"5.5p1", "5.5p2", -1},
+		{"5.5p10", "5.5p10", 0},
+		{"5.5p1", "5.5p10", -1},
+		{"10xyz", "10.1xyz", -1},
+		{"xyz10", "xyz10", 0},
+		{"xyz10", "xyz10.1", -1},
+		{"xyz.4", "xyz.4", 0},
+		{"xyz.4", "8", -1},
+		{"xyz.4", "2", -1},
+		{"5.5p2", "5.6p1", -1},
+		{"5.6p1", "6.5p1", -1},
+		{"6.0.rc1", "6.0", 1},
+		{"10b2", "10a1", 1},
+		{"10a2", "10b2", -1},
+		{"1.0aa", "1.0aa", 0},
+		{"1.0a", "1.0aa", -1},
+		{"10.0001", "10.0001", 0},
+		{"10.0001", "10.1", 0},
+		{"10.0001", "10.0039", -1},
+		{"4.999.9", "5.0", -1},
+		{"20101121", "20101121", 0},
+		{"20101121", "20101122", -1},
+		{"2_0", "2_0", 0},
+		{"2.0", "2_0", 0},
+		{"a", "a", 0},
+		{"a+", "a+", 0},
+		{"a+", "a_", 0},
+		{"+a", "+a", 0},
+		{"+a", "_a", 0},
+		{"+_", "+_", 0},
+		{"_+", "+_", 0},
+		{"+", "_", 0},
+		{"1.0~rc1", "1.0~rc1", 0},
+		{"1.0~rc1", "1.0", -1},
+		{"1.0~rc1", "1.0~rc2", -1},
+		{"1.0~rc1~git123", "1.0~rc1~git123", 0},
+		{"1.0~rc1~git123", "1.0~rc1", -1},
+		{"1.0^", "1.0^", 0},
+		{"1.0^", "1.0", 1},
+		{"1.0^git1", "1.0^git1", 0},
+		{"1.0^git1", "1.0", 1},
+		{"1.0^git1", "1.0^git2", -1},
+		{"1.0^git1", "1.01", -1},
+		{"1.0^20160101", "1.0^20160101", 0},
+		{"1.0^20160101", "1.0.1", -1},
+		{"1.0^20160101^git1", "1.0^20160101^git1", 0},
+		{"1.0^20160102", "1.0^20160101^git1", 1},
+		{"1.0~rc1^git1", "1.0~rc1^git1", 0},
+		{"1.0~rc1^git1", "1.0~rc1", 1},
+		{"1.0^git1~pre", "1.0^git1~pre", 0},
+		{"1.0^git1", "1.0^git1~pre", 1},
+	}
+	for _, test := range tests {
+		t.Run(test.a+" "+test.b, func(t *testing.T) {
+			assert.Equal(t, test.expected, rpmVerCmp(test.a, test.b))
+			assert.Equal(t, -test.expected, rpmVerCmp(test.b, test.a))
+		})
+	}
+}
+
+func TestRPMVersion_compare(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.0-1", "1.0-1", 0},
+		{"0:1.0-1", "1.0-1", 0},
+		{"1:1.0-1", "2.0-1", 1},
+		{"1.0-1", "1.0-2", -1},
+		{"1.0-1.el8", "1.0-1.el9", -1},
+		{"1.0", "1.0-1", -1},
+		{"1.0~beta-1", "1.0-1", -1},
+	}
+	for _, test := range tests {
+		t.Run(test.a+" "+test.b, func(t *testing.T) {
+			a, b := MustRPMVersion(test.a), MustRPMVersion(test.b)
+			assert.Equal(t, test.expected, a.compare(b))
+			assert.Equal(t, test.expected == 0, a.Equal(b))
+			assert.Equal(t, test.expected < 0, a.Less(b))
+		})
+	}
+}
+
+func FuzzNewRPMVersion(f *testing.F) {
+	fuzzVersionRoundTrip(f, ParseRPMVersion,
+		"1:2.34-5.el9", "1.0~rc1", "1.0^git1", "0:1.0", "1.0-1-2")
+}
@@ -0,0 +1,510 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const DefaultVersionScheme = "semver"
+
+var (
+	ErrUnknownVersionScheme     = errors.New("unknown version scheme")
+	ErrUnknownConstraintDialect = errors.New("unknown constraint dialect")
+)
+
+// Ties a version format to the functions needed to parse it.
+// Versions compare themselves through Version.Equal and Version.Less.
+type VersionScheme struct {
+	// Name of the scheme, e.g. "semver".
+	Name string
+	// Parse turns a string into a Version of this scheme.
+	Parse VersionParser
+	// ParseConstraint turns a string into a constraint tree.
+	// Defaults to using the package level ParseConstraint with Parse.
+	ParseConstraint func(constraint string) (VersionConstraint, error)
+	// Dialects parse other constraint syntaxes over versions of this scheme by name,
+	// e.g. npm ranges. Constraints parsed by a dialect implement DialectConstraint.
+	Dialects map[string]func(constraint string) (VersionConstraint, error)
+}
+
+var (
+	SemanticVersionScheme = VersionScheme{
+		Name:  "semver",
+		Parse: ParseSemanticVersion,
+		Dialects: map[string]func(constraint string) (VersionConstraint, error){
+			NPMDialect:   ParseNPMRange,
+			CargoDialect: ParseCargoRequirement,
+		},
+	}
+	SequenceVersionScheme = VersionScheme{
+		Name:  "sequence",
+		Parse: ParseSequenceVersion,
+	}
+)
+
+// DefaultVersionSchemes contains all version schemes known to this library.
+var DefaultVersionSchemes = NewVersionSchemeRegistry(
+	SemanticVersionScheme,
+	SequenceVersionScheme,
+	CalendarVersionScheme(DefaultCalendarVersionFormat),
+	PEP440VersionScheme,
+	DebianVersionScheme,
+	RPMVersionScheme,
+	MavenVersionScheme,
+	GoModuleVersionScheme,
+)
+
+// Registry of named version schemes.
+type VersionSchemeRegistry struct {
+	// schemes indexed by name
+	schemes map[string]VersionScheme
+}
+
+func NewVersionSchemeRegistry(schemes ...VersionScheme) *VersionSchemeRegistry {
+	r := &VersionSchemeRegistry{
+		schemes: map[string]VersionScheme{},
+	}
+	for _, s := range schemes {
+		if err := r.Register(s); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
+
+func (r *VersionSchemeRegistry) Register(scheme VersionScheme) error {
+	if len(scheme.Name) == 0 || scheme.Parse == nil {
+		return fmt.Errorf("version scheme needs a name and parser")
+	}
+	if _, ok := r.schemes[scheme.Name]; ok {
+		return fmt.Errorf("version scheme %q already registered", scheme.Name)
+	}
+	r.schemes[scheme.Name] = withSchemeDefaults(scheme)
+	return nil
+}
+
+func withSchemeDefaults(scheme VersionScheme) VersionScheme {
+	if scheme.ParseConstraint == nil {
+		parse := scheme.Parse
+		scheme.ParseConstraint = func(constraint string) (VersionConstraint, error) {
+			c, err := ParseConstraint(constraint, parse)
+			if err != nil {
+				return nil, err
+			}
+			return *c, nil
+		}
+	}
+	return scheme
+}
+
+// Get returns the scheme registered under the given name.
+// An empty name returns the DefaultVersionScheme.
+// Registries containing the "calver" scheme also return
+// a CalendarVersionScheme for any "calver:<format>" name.
+func (r *VersionSchemeRegistry) Get(name string) (VersionScheme, error) {
+	if len(name) == 0 {
+		name = DefaultVersionScheme
+	}
+	if scheme, ok := r.schemes[name]; ok {
+		return scheme, nil
+	}
+	if format := strings.TrimPrefix(name, "calver:"); format != name {
+		if _, ok := r.schemes[calendarVersionSchemeName(DefaultCalendarVersionFormat)]; ok {
+			if _, _, err := parseCalendarVersionFormat(format); err != nil {
+				return VersionScheme{}, fmt.Errorf("%w: %q: %v", ErrUnknownVersionScheme, name, err)
+			}
+			return withSchemeDefaults(CalendarVersionScheme(format)), nil
+		}
+	}
+	return VersionScheme{}, fmt.Errorf("%w: %q", ErrUnknownVersionScheme, name)
+}
+
+func (r *VersionSchemeRegistry) ParseVersion(scheme, v string) (Version, error) {
+	s, err := r.Get(scheme)
+	if err != nil {
+		return nil, err
+	}
+	return s.Parse(v)
+}
+
+func (r *VersionSchemeRegistry) ParseConstraint(scheme, constraint string) (VersionConstraint, error) {
+	s, err := r.Get(scheme)
+	if err != nil {
+		return nil, err
+	}
+	return s.ParseConstraint(constraint)
+}
+
+// ParseDialectConstraint parses a constraint using a dialect of the given scheme.
+func (r *VersionSchemeRegistry) ParseDialectConstraint(scheme, dialect, constraint string) (VersionConstraint, error) {
+	s, err := r.Get(scheme)
+	if err != nil {
+		return nil, err
+	}
+	parse, ok := s.Dialects[dialect]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q of version scheme %q", ErrUnknownConstraintDialect, dialect, s.Name)
+	}
+	return parse(constraint)
+}
+
+// Compare returns -1 if a < b, 0 if a == b and 1 if a > b.
+// Versions of different or unregistered schemes can not be compared.
+func (r *VersionSchemeRegistry) Compare(a, b Version) (int, error) {
+	if a.Scheme() != b.Scheme() {
+		return 0, fmt.Errorf(
+			"cannot compare %q version %s with %q version %s",
+			a.Scheme(), a, b.Scheme(), b)
+	}
+	if _, err := r.Get(a.Scheme()); err != nil {
+		return 0, err
+	}
+	return compareVersions(a, b), nil
+}
+
+// Ensures all versions of the project belong to the scheme declared by the project,
+// or to a scheme the project migrated from.
+func (r *VersionSchemeRegistry) ValidateProject(project Project) error {
+	s, err := r.Get(project.Scheme)
+	if err != nil {
+		return err
+	}
+	schemes := map[string]struct{}{s.Name: {}}
+	for _, name := range project.Migrations.Schemes() {
+		if _, err := r.Get(name); err != nil {
+			return err
+		}
+		schemes[name] = struct{}{}
+	}
+	for _, pv := range project.Versions {
+		if _, ok := schemes[pv.Version.Scheme()]; !ok {
+			return fmt.Errorf(
+				"project %q uses version scheme %q, but version %s is %q",
+				project.Name, s.Name, pv.Version, pv.Version.Scheme())
+		}
+	}
+	return nil
+}
+
+// MarshalProject serializes a project into JSON.
+// ConstraintAND nodes are flattened, ConstraintOR nodes can not be serialized.
+func (r *VersionSchemeRegistry) MarshalProject(project Project) ([]byte, error) {
+	if err := r.ValidateProject(project); err != nil {
+		return nil, err
+	}
+
+	scheme := project.Scheme
+	if len(scheme) == 0 {
+		scheme = DefaultVersionScheme
+	}
+
+	pj := projectJSON{
+		Name:   project.Name,
+		Scheme: project.Scheme,
+	}
+	for _, m := range project.Migrations {
+		mj := schemeMigrationJSON{
+			From:   m.From,
+			Before: m.Before.String(),
+		}
+		if m.Before.Scheme() != scheme {
+			mj.Scheme = m.Before.Scheme()
+		}
+		pj.Migrations = append(pj.Migrations, mj)
+	}
+	for _, pv := range project.Versions {
+		pvj := projectVersionJSON{
+			Version:    pv.Version.String(),
+			Yanked:     pv.Yanked,
+			Deprecated: pv.Deprecated,
+			Retracted:  pv.Retracted,
+		}
+		if pv.Version.Scheme() != scheme {
+			pvj.Scheme = pv.Version.Scheme()
+		}
+		var err error
+		if pvj.Dependencies, err = marshalDependencies(pv.Dependencies, scheme); err != nil {
+			return nil, fmt.Errorf("project %q version %q dependency %w", project.Name, pvj.Version, err)
+		}
+		if pvj.Conflicts, err = marshalDependencies(pv.Conflicts, scheme); err != nil {
+			return nil, fmt.Errorf("project %q version %q conflict %w", project.Name, pvj.Version, err)
+		}
+		pvj.Provides = marshalCapabilities(pv.Provides, scheme)
+		pvj.Replaces = marshalCapabilities(pv.Replaces, scheme)
+		for _, f := range pv.Features {
+			fj := featureJSON{Name: f.Name}
+			if fj.Dependencies, err = marshalDependencies(f.Dependencies, scheme); err != nil {
+				return nil, fmt.Errorf(
+					"project %q version %q feature %q dependency %w", project.Name, pvj.Version, f.Name, err)
+			}
+			pvj.Features = append(pvj.Features, fj)
+		}
+		pj.Versions = append(pj.Versions, pvj)
+	}
+	return json.Marshal(pj)
+}
+
+func marshalDependencies(deps []Dependency, projectScheme string) ([]dependencyJSON, error) {
+	var djs []dependencyJSON
+	for _, dep := range deps {
+		dj := dependencyJSON{Name: dep.Name, Optional: dep.Optional, Features: dep.Features}
+		if dep.Kind != DependencyRuntime {
+			dj.Kind = dep.Kind.String()
+		}
+		if dep.Marker != nil {
+			dj.Marker = dep.Marker.String()
+		}
+		constraints, err := flattenConstraints(ConstraintAND(dep.Constraints))
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", dep.Name, err)
+		}
+		for _, c := range constraints {
+			for _, v := range c.Versions() {
+				if v.Scheme() != projectScheme {
+					dj.Scheme = v.Scheme()
+				}
+			}
+			var dialect string
+			if dc, ok := c.(DialectConstraint); ok {
+				dialect = dc.Dialect()
+			}
+			if len(dj.Constraints) != 0 && dialect != dj.Dialect {
+				return nil, fmt.Errorf("%q: mixed constraint dialects %q and %q", dep.Name, dj.Dialect, dialect)
+			}
+			dj.Dialect = dialect
+			dj.Constraints = append(dj.Constraints, c.String())
+		}
+		djs = append(djs, dj)
+	}
+	return djs, nil
+}
+
+// Flattens AND nodes into a list of constraints, which a dependency ANDs again.
+// OR nodes have no syntax in the semver scheme and are rejected.
+func flattenConstraints(c VersionConstraint) ([]VersionConstraint, error) {
+	switch c := c.(type) {
+	case ConstraintAND:
+		var flat []VersionConstraint
+		for _, con := range c {
+			f, err := flattenConstraints(con)
+			if err != nil {
+				return nil, err
+			}
+			flat = append(flat, f...)
+		}
+		return flat, nil
+	case ConstraintOR:
+		return nil, fmt.Errorf("cannot serialize OR constraint %q", c.String())
+	}
+	return []VersionConstraint{c}, nil
+}
+
+// UnmarshalProject deserializes a project from JSON.
+// Versions are parsed using the scheme declared by the version or the project,
+// constraints using the scheme declared by the dependency or the project.
+func (r *VersionSchemeRegistry) UnmarshalProject(data []byte) (Project, error) {
+	var pj projectJSON
+	if err := json.Unmarshal(data, &pj); err != nil {
+		return Project{}, err
+	}
+
+	project := Project{
+		Name:   pj.Name,
+		Scheme: pj.Scheme,
+	}
+	for _, mj := range pj.Migrations {
+		scheme := mj.Scheme
+		if len(scheme) == 0 {
+			scheme = pj.Scheme
+		}
+		v, err := r.ParseVersion(scheme, mj.Before)
+		if err != nil {
+			return Project{}, fmt.Errorf("project %q migration from %q: %w", pj.Name, mj.From, err)
+		}
+		project.Migrations = append(project.Migrations, SchemeMigration{From: mj.From, Before: v})
+	}
+	for _, pvj := range pj.Versions {
+		scheme := pvj.Scheme
+		if len(scheme) == 0 {
+			scheme = pj.Scheme
+		}
+		v, err := r.ParseVersion(scheme, pvj.Version)
+		if err != nil {
+			return Project{}, fmt.Errorf("project %q version %q: %w", pj.Name, pvj.Version, err)
+		}
+		pv := ProjectVersion{
+			Version:    v,
+			Yanked:     pvj.Yanked,
+			Deprecated: pvj.Deprecated,
+			Retracted:  pvj.Retracted,
+		}
+		if pv.Dependencies, err = r.unmarshalDependencies(pvj.Dependencies, pj.Scheme); err != nil {
+			return Project{}, fmt.Errorf("project %q version %q dependency %w", pj.Name, pvj.Version, err)
+		}
+		if pv.Conflicts, err = r.unmarshalDependencies(pvj.Conflicts, pj.Scheme); err != nil {
+			return Project{}, fmt.Errorf("project %q version %q conflict %w", pj.Name, pvj.Version, err)
+		}
+		if pv.Provides, err = r.unmarshalCapabilities(pvj.Provides, pj.Scheme); err != nil {
+			return Project{}, fmt.Errorf("project %q version %q capability %w", pj.Name, pvj.Version, err)
+		}
+		if pv.Replaces, err = r.unmarshalCapabilities(pvj.Replaces, pj.Scheme); err != nil {
+			return Project{}, fmt.Errorf("project %q version %q replacement %w", pj.Name, pvj.Version, err)
+		}
+		for _, fj := range pvj.Features {
+			f := Feature{Name: fj.Name}
+			if f.Dependencies, err = r.unmarshalDependencies(fj.Dependencies, pj.Scheme); err != nil {
+				return Project{}, fmt.Errorf(
+					"project %q version %q feature %q dependency %w", pj.Name, pvj.Version, fj.Name, err)
+			}
+			pv.Features = append(pv.Features, f)
+		}
+		project.Versions = append(project.Versions, pv)
+	}
+	return project, nil
+}
+
+func marshalCapabilities(capabilities []Capability, projectScheme string) []capabilityJSON {
+	var cjs []capabilityJSON
+	for _, c := range capabilities {
+		cj := capabilityJSON{Name: c.Name}
+		if c.Version != nil {
+			cj.Version = c.Version.String()
+			if c.Version.Scheme() != projectScheme {
+				cj.Scheme = c.Version.Scheme()
+			}
+		}
+		cjs = append(cjs, cj)
+	}
+	return cjs
+}
+
+func (r *VersionSchemeRegistry) unmarshalCapabilities(cjs []capabilityJSON, projectScheme string) ([]Capability, error) {
+	var capabilities []Capability
+	for _, cj := range cjs {
+		c := Capability{Name: cj.Name}
+		if len(cj.Version) != 0 {
+			scheme := cj.Scheme
+			if len(scheme) == 0 {
+				scheme = projectScheme
+			}
+			v, err := r.ParseVersion(scheme, cj.Version)
+			if err != nil {
+				return nil, fmt.Errorf("%q: %w", cj.Name, err)
+			}
+			c.Version = v
+		}
+		capabilities = append(capabilities, c)
+	}
+	return capabilities, nil
+}
+
+func (r *VersionSchemeRegistry) unmarshalDependencies(djs []dependencyJSON, projectScheme string) ([]Dependency, error) {
+	var deps []Dependency
+	for _, dj := range djs {
+		scheme := dj.Scheme
+		if len(scheme) == 0 {
+			scheme = projectScheme
+		}
+		dep := Dependency{Name: dj.Name, Optional: dj.Optional, Features: dj.Features}
+		if len(dj.Kind) != 0 {
+			kind, err := ParseDependencyKind(dj.Kind)
+			if err != nil {
+				return nil, fmt.Errorf("%q: %w", dj.Name, err)
+			}
+			dep.Kind = kind
+		}
+		if len(dj.Marker) != 0 {
+			marker, err := ParseMarker(dj.Marker)
+			if err != nil {
+				return nil, fmt.Errorf("%q: %w", dj.Name, err)
+			}
+			dep.Marker = marker
+		}
+		for _, cs := range dj.Constraints {
+			var (
+				c   VersionConstraint
+				err error
+			)
+			if len(dj.Dialect) != 0 {
+				c, err = r.ParseDialectConstraint(scheme, dj.Dialect, cs)
+			} else {
+				c, err = r.ParseConstraint(scheme, cs)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%q: %w", dj.Name, err)
+			}
+			dep.Constraints = append(dep.Constraints, c)
+		}
+		deps = append(deps, dep)
+	}
+	return deps, nil
+}
+
+type projectJSON struct {
+	Name       string                `json:"name"`
+	Scheme     string                `json:"scheme,omitempty"`
+	Migrations []schemeMigrationJSON `json:"migrations,omitempty"`
+	Versions   []projectVersionJSON  `json:"versions"`
+}
+
+type schemeMigrationJSON struct {
+	From   string `json:"from"`
+	Before string `json:"before"`
+	// Scheme of the Before version, if different from the project scheme.
+	Scheme string `json:"scheme,omitempty"`
+}
+
+type projectVersionJSON struct {
+	Version string `json:"version"`
+	// Scheme of the version, if different from the project scheme.
+	Scheme       string           `json:"scheme,omitempty"`
+	Dependencies []dependencyJSON `json:"dependencies,omitempty"`
+	Conflicts    []dependencyJSON `json:"conflicts,omitempty"`
+	Provides     []capabilityJSON `json:"provides,omitempty"`
+	Replaces     []capabilityJSON `json:"replaces,omitempty"`
+	Features     []featureJSON    `json:"features,omitempty"`
+	Yanked       bool             `json:"yanked,omitempty"`
+	Deprecated   string           `json:"deprecated,omitempty"`
+	Retracted    string           `json:"retracted,omitempty"`
+}
+
+type capabilityJSON struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	// Scheme of the version, if different from the project scheme.
+	Scheme string `json:"scheme,omitempty"`
+}
+
+type dependencyJSON struct {
+	Name string `json:"name"`
+	// Scheme of the constraints, if different from the project scheme.
+	Scheme      string   `json:"scheme,omitempty"`
+	Constraints []string `json:"constraints,omitempty"`
+	// Dialect of the scheme the constraints are written in, e.g. "npm".
+	Dialect  string   `json:"dialect,omitempty"`
+	Optional bool     `json:"optional,omitempty"`
+	Features []string `json:"features,omitempty"`
+	// Kind of the dependency, defaults to "runtime".
+	Kind string `json:"kind,omitempty"`
+	// Environment marker expression.
+	Marker string `json:"marker,omitempty"`
+}
+
+type featureJSON struct {
+	Name         string           `json:"name"`
+	Dependencies []dependencyJSON `json:"dependencies,omitempty"`
+}
+
+// Default comparison using Version.Equal and Version.Less.
+func compareVersions(a, b Version) int {
+	switch {
+	case a.Equal(b):
+		return 0
+	case a.Less(b):
+		return -1
+	default:
+		return 1
+	}
+}
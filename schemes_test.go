@@ -0,0 +1,211 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionSchemeRegistry(t *testing.T) {
+	r := NewVersionSchemeRegistry(SemanticVersionScheme, SequenceVersionScheme)
+
+	require.EqualError(t, r.Register(SemanticVersionScheme),
+		`version scheme "semver" already registered`)
+
+	_, err := r.Get("xxx")
+	assert.True(t, errors.Is(err, ErrUnknownVersionScheme))
+
+	s, err := r.Get("")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultVersionScheme, s.Name)
+
+	sv, err := r.ParseVersion("semver", "v1.2.0")
+	require.NoError(t, err)
+	assert.Equal(t, "semver", sv.Scheme())
+
+	seq, err := r.ParseVersion("sequence", "12")
+	require.NoError(t, err)
+	assert.Equal(t, "sequence", seq.Scheme())
+
+	c, err := r.ParseConstraint("sequence", "=12")
+	require.NoError(t, err)
+	assert.True(t, c.Matches(seq))
+
+	cmp, err := r.Compare(sv, MustSemanticVersion("v1.3.0"))
+	require.NoError(t, err)
+	assert.Equal(t, -1, cmp)
+
+	_, err = r.Compare(sv, seq)
+	require.EqualError(t, err,
+		`cannot compare "semver" version 1.2.0 with "sequence" version 12`)
+}
+
+func TestVersionSchemeRegistry_ValidateProject(t *testing.T) {
+	err := DefaultVersionSchemes.ValidateProject(Project{
+		Name:   "A",
+		Scheme: "sequence",
+		Versions: []ProjectVersion{
+			{Version: MustSemanticVersion("1.0.0")},
+		},
+	})
+	require.EqualError(t, err,
+		`project "A" uses version scheme "sequence", but version 1.0.0 is "semver"`)
+}
+
+func TestVersionSchemeRegistry_MarshalProject(t *testing.T) {
+	project := Project{
+		Name:   "A",
+		Scheme: "sequence",
+		Versions: []ProjectVersion{
+			{
+				Version:   MustSequenceVersion("2"),
+				Retracted: "broken build",
+				Dependencies: []Dependency{
+					{Name: "B", Constraints: []VersionConstraint{
+						*NewConstraint(Equal, MustSemanticVersion("1.0.0")),
+					}},
+					{Name: "C", Constraints: []VersionConstraint{
+						*NewConstraint(NotEqual, MustSequenceVersion("4")),
+					}, Kind: DependencyBuild},
+				},
+			},
+		},
+	}
+
+	data, err := DefaultVersionSchemes.MarshalProject(project)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"name": "A",
+		"scheme": "sequence",
+		"versions": [
+			{
+				"version": "2",
+				"retracted": "broken build",
+				"dependencies": [
+					{"name": "B", "scheme": "semver", "constraints": ["=1.0.0"]},
+					{"name": "C", "constraints": ["!=4"], "kind": "build"}
+				]
+			}
+		]
+	}`, string(data))
+
+	decoded, err := DefaultVersionSchemes.UnmarshalProject(data)
+	require.NoError(t, err)
+	assert.Equal(t, project, decoded)
+
+	_, err = DefaultVersionSchemes.UnmarshalProject([]byte(`{
+		"name": "A",
+		"versions": [{"version": "1.0.0", "dependencies": [{"name": "B", "kind": "optional"}]}]
+	}`))
+	require.EqualError(t, err, `project "A" version "1.0.0" dependency "B": invalid dependency kind "optional"`)
+}
+
+func TestVersionSchemeRegistry_MarshalProject_dialects(t *testing.T) {
+	tests := []struct {
+		dialect, constraint string
+		parse               func(string) (VersionConstraint, error)
+	}{
+		{dialect: "npm", constraint: "^1.2.3 || 2.x", parse: ParseNPMRange},
+		{dialect: "cargo", constraint: "1.2", parse: ParseCargoRequirement},
+		{dialect: "cargo", constraint: "~1.2, <1.2.5", parse: ParseCargoRequirement},
+	}
+	for _, test := range tests {
+		t.Run(test.dialect+" "+test.constraint, func(t *testing.T) {
+			c, err := test.parse(test.constraint)
+			require.NoError(t, err)
+			project := Project{
+				Name: "A",
+				Versions: []ProjectVersion{
+					{
+						Version: MustSemanticVersion("1.0.0"),
+						Dependencies: []Dependency{
+							{Name: "B", Constraints: []VersionConstraint{c}},
+						},
+					},
+				},
+			}
+
+			data, err := DefaultVersionSchemes.MarshalProject(project)
+			require.NoError(t, err)
+			assert.JSONEq(t, fmt.Sprintf(`{
+				"name": "A",
+				"versions": [
+					{
+						"version": "1.0.0",
+						"dependencies": [{"name": "B", "dialect": %q, "constraints": [%q]}]
+					}
+				]
+			}`, test.dialect, test.constraint), string(data))
+
+			decoded, err := DefaultVersionSchemes.UnmarshalProject(data)
+			require.NoError(t, err)
+			assert.Equal(t, project, decoded)
+		})
+	}
+
+	npm, err := ParseNPMRange("^1.2.3")
+	require.NoError(t, err)
+	_, err = DefaultVersionSchemes.MarshalProject(Project{
+		Name: "A",
+		Versions: []ProjectVersion{
+			{
+				Version: MustSemanticVersion("1.0.0"),
+				Dependencies: []Dependency{
+					{Name: "B", Constraints: []VersionConstraint{
+						npm, *NewConstraint(Less, MustSemanticVersion("1.5.0")),
+					}},
+				},
+			},
+		},
+	})
+	require.EqualError(t, err,
+		`project "A" version "1.0.0" dependency "B": mixed constraint dialects "npm" and ""`)
+
+	_, err = DefaultVersionSchemes.UnmarshalProject([]byte(`{
+		"name": "A",
+		"versions": [{"version": "1.0.0", "dependencies": [{"name": "B", "dialect": "gem", "constraints": ["~> 1.0"]}]}]
+	}`))
+	require.EqualError(t, err,
+		`project "A" version "1.0.0" dependency "B": unknown constraint dialect: "gem" of version scheme "semver"`)
+}
+
+func TestVersionSchemeRegistry_MarshalProject_constraintTrees(t *testing.T) {
+	project := func(constraints ...VersionConstraint) Project {
+		return Project{
+			Name: "A",
+			Versions: []ProjectVersion{
+				{
+					Version: MustSemanticVersion("1.0.0"),
+					Dependencies: []Dependency{
+						{Name: "B", Constraints: constraints},
+					},
+				},
+			},
+		}
+	}
+	atLeast1 := *NewConstraint(GreaterOrEqual, MustSemanticVersion("1.0.0"))
+	below2 := *NewConstraint(Less, MustSemanticVersion("2.0.0"))
+	not15 := *NewConstraint(NotEqual, MustSemanticVersion("1.5.0"))
+
+	// AND nodes are flattened into the constraints of the dependency.
+	data, err := DefaultVersionSchemes.MarshalProject(
+		project(ConstraintAND{atLeast1, ConstraintAND{below2, not15}}))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"name": "A",
+		"versions": [
+			{"version": "1.0.0", "dependencies": [{"name": "B", "constraints": [">=1.0.0", "<2.0.0", "!=1.5.0"]}]}
+		]
+	}`, string(data))
+
+	decoded, err := DefaultVersionSchemes.UnmarshalProject(data)
+	require.NoError(t, err)
+	assert.Equal(t, project(atLeast1, below2, not15), decoded)
+
+	_, err = DefaultVersionSchemes.MarshalProject(project(ConstraintAND{atLeast1, ConstraintOR{below2, not15}}))
+	require.EqualError(t, err,
+		`project "A" version "1.0.0" dependency "B": cannot serialize OR constraint "<2.0.0 || !=1.5.0"`)
+}
@@ -1,13 +1,51 @@
 package main
 
+import (
+	"fmt"
+	"strings"
+)
+
 type Project struct {
-	Name     string
+	Name string
+	// Name of the VersionScheme used by this project,
+	// defaults to DefaultVersionScheme.
+	Scheme   string
 	Versions []ProjectVersion
+	// Previous version schemes of this project.
+	Migrations SchemeMigrations
 }
 
 type ProjectVersion struct {
 	Version      Version
 	Dependencies []Dependency
+	// Versions of other projects this version cannot be installed with.
+	// Conflicts do not add projects to the dependency tree.
+	Conflicts []Dependency
+	// Virtual capabilities this version provides.
+	// Dependencies on a capability are satisfied by any provider.
+	Provides []Capability
+	// Named feature sets that add dependencies, when enabled.
+	Features []Feature
+	// Projects this version stands in for, e.g. after a rename.
+	// Dependencies on a replaced project may be satisfied by this version,
+	// but the replaced project cannot be installed alongside it.
+	Replaces []Capability
+	// Yanked versions are only selected, if locked or pinned exactly by a selected dependent.
+	Yanked bool
+	// Deprecation message, the version is deprecated if not empty.
+	// Deprecated versions are only selected, if no other version fits.
+	Deprecated string
+	// Retraction reason, the version is retracted if not empty.
+	// Retracted versions are only selected, if no other version fits.
+	Retracted string
+}
+
+// Virtual capability, like "mail-transport-agent", that may be provided by multiple projects.
+type Capability struct {
+	Name string
+	// Version of the capability, if any.
+	// Unversioned capabilities only satisfy dependencies without constraints.
+	Version Version
 }
 
 type ProjectVersionsDescending []ProjectVersion
@@ -18,11 +56,63 @@ func (a ProjectVersionsDescending) Less(i, j int) bool { return a[j].Version.Les
 
 type Dependency struct {
 	Name        string
-	Constraints []Constraint
+	Constraints []VersionConstraint
+	// Optional dependencies only constrain the project,
+	// if it is installed because of another dependency.
+	Optional bool
+	// Features of the project that must be enabled.
+	Features []string
+	// Kind of the dependency, defaults to DependencyRuntime.
+	Kind DependencyKind
+	// Environments the dependency applies to, applies to all environments if nil.
+	Marker Marker
+}
+
+// Describes when a dependency is needed.
+type DependencyKind int
+
+const (
+	// Needed to run the project.
+	DependencyRuntime DependencyKind = iota
+	// Needed to build the project.
+	DependencyBuild
+	// Needed to run tests of the project, only resolved for root dependencies.
+	DependencyTest
+	// Development tooling, like linters and code generators, only resolved for root dependencies.
+	DependencyDev
+	// Expected to be installed alongside the project.
+	// Peer dependencies only constrain the project,
+	// if it is installed because of another dependency.
+	DependencyPeer
+)
+
+var dependencyKindNames = map[DependencyKind]string{
+	DependencyRuntime: "runtime",
+	DependencyBuild:   "build",
+	DependencyTest:    "test",
+	DependencyDev:     "dev",
+	DependencyPeer:    "peer",
+}
+
+func (k DependencyKind) String() string {
+	if name, ok := dependencyKindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("DependencyKind(%d)", int(k))
+}
+
+// ParseDependencyKind returns the DependencyKind with the given name.
+func ParseDependencyKind(s string) (DependencyKind, error) {
+	for kind, name := range dependencyKindNames {
+		if name == s {
+			return kind, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid dependency kind %q", s)
 }
 
 // ConstraintAND is AND of all constraints.
-type ConstraintAND []Constraint
+type ConstraintAND []VersionConstraint
 
 func (c ConstraintAND) Matches(v Version) bool {
 	for _, con := range c {
@@ -32,3 +122,47 @@ func (c ConstraintAND) Matches(v Version) bool {
 	}
 	return true
 }
+
+func (c ConstraintAND) String() string {
+	return joinConstraints(c, ", ")
+}
+
+func (c ConstraintAND) Versions() []Version {
+	return constraintVersions(c)
+}
+
+// ConstraintOR is OR of all constraints.
+type ConstraintOR []VersionConstraint
+
+func (c ConstraintOR) Matches(v Version) bool {
+	for _, con := range c {
+		if con.Matches(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c ConstraintOR) String() string {
+	return joinConstraints(c, " || ")
+}
+
+func (c ConstraintOR) Versions() []Version {
+	return constraintVersions(c)
+}
+
+func joinConstraints(constraints []VersionConstraint, sep string) string {
+	var s []string
+	for _, c := range constraints {
+		s = append(s, c.String())
+	}
+	return strings.Join(s, sep)
+}
+
+func constraintVersions(constraints []VersionConstraint) []Version {
+	var versions []Version
+	for _, c := range constraints {
+		versions = append(versions, c.Versions()...)
+	}
+	return versions
+}
@@ -28,26 +28,26 @@ func TestConstraint(t *testing.T) {
 		},
 		{
 			op:          Greater,
-			versionBase: MustSemanticVersion("v1.6.0"),
-			versionTest: MustSemanticVersion("v1.2.0"),
+			versionBase: MustSemanticVersion("v1.2.0"),
+			versionTest: MustSemanticVersion("v1.6.0"),
 			result:      true,
 		},
 		{
 			op:          Less,
-			versionBase: MustSemanticVersion("v1.0.0"),
-			versionTest: MustSemanticVersion("v1.2.0"),
+			versionBase: MustSemanticVersion("v1.2.0"),
+			versionTest: MustSemanticVersion("v1.0.0"),
 			result:      true,
 		},
 		{
 			op:          GreaterOrEqual,
-			versionBase: MustSemanticVersion("v1.5.0"),
-			versionTest: MustSemanticVersion("v1.2.0"),
+			versionBase: MustSemanticVersion("v1.2.0"),
+			versionTest: MustSemanticVersion("v1.5.0"),
 			result:      true,
 		},
 		{
 			op:          LessOrEqual,
-			versionBase: MustSemanticVersion("v1.1.0"),
-			versionTest: MustSemanticVersion("v1.2.0"),
+			versionBase: MustSemanticVersion("v1.2.0"),
+			versionTest: MustSemanticVersion("v1.1.0"),
 			result:      true,
 		},
 	}
@@ -62,11 +62,23 @@ func TestConstraint(t *testing.T) {
 }
 
 func TestParseConstraint(t *testing.T) {
-	c, err := ParseConstraint(">v1.2.3", ParseSemanticVersion)
-	require.NoError(t, err)
+	tests := map[string]Operator{
+		"=v1.2.3":  Equal,
+		"!=v1.2.3": NotEqual,
+		">v1.2.3":  Greater,
+		"<v1.2.3":  Less,
+		">=v1.2.3": GreaterOrEqual,
+		"<=v1.2.3": LessOrEqual,
+	}
+	for constraint, op := range tests {
+		t.Run(constraint, func(t *testing.T) {
+			c, err := ParseConstraint(constraint, ParseSemanticVersion)
+			require.NoError(t, err)
 
-	assert.Equal(t, Greater, c.operator)
-	assert.Equal(t, "1.2.3", c.version.String())
+			assert.Equal(t, op, c.operator)
+			assert.Equal(t, "1.2.3", c.version.String())
+		})
+	}
 }
 
 func TestParseConstraint_unknownOp(t *testing.T) {
@@ -78,3 +90,86 @@ func TestParseConstraint_parserError(t *testing.T) {
 	_, err := ParseConstraint("=vxxx", ParseSemanticVersion)
 	require.EqualError(t, err, "Invalid Semantic Version")
 }
+
+func TestExactVersion(t *testing.T) {
+	pep440 := func(s string) (VersionConstraint, error) {
+		and, err := ParsePEP440Specifier(s)
+		if err != nil {
+			return nil, err
+		}
+		return and[0], nil
+	}
+	semver := func(s string) (VersionConstraint, error) {
+		return ParseConstraint(s, ParseSemanticVersion)
+	}
+
+	tests := []struct {
+		constraint string
+		parse      func(string) (VersionConstraint, error)
+		// empty, if the constraint is not exact.
+		expected string
+	}{
+		{constraint: "=1.2.3", parse: semver, expected: "1.2.3"},
+		{constraint: ">=1.2.3", parse: semver},
+		{constraint: "1.2.3", parse: ParseNPMRange, expected: "1.2.3"},
+		{constraint: "=1.2.3", parse: ParseNPMRange, expected: "1.2.3"},
+		{constraint: "1.2.x", parse: ParseNPMRange},
+		{constraint: "1.2.3 || 1.2.4", parse: ParseNPMRange},
+		{constraint: "=1.2.3", parse: ParseCargoRequirement, expected: "1.2.3"},
+		{constraint: "1.2.3", parse: ParseCargoRequirement},
+		{constraint: "==1.2.3", parse: pep440, expected: "1.2.3"},
+		{constraint: "==1.2.*", parse: pep440},
+		{constraint: "===1.2.3", parse: pep440},
+		{constraint: "[1.2.3]", parse: ParseMavenVersionRange, expected: "1.2.3"},
+		{constraint: "[1.2.3,1.2.3]", parse: ParseMavenVersionRange},
+		{constraint: "1.2.3", parse: ParseMavenVersionRange},
+	}
+	for _, test := range tests {
+		t.Run(test.constraint, func(t *testing.T) {
+			c, err := test.parse(test.constraint)
+			require.NoError(t, err)
+			exact, ok := c.(ExactConstraint)
+			require.True(t, ok)
+
+			v, ok := exact.ExactVersion()
+			if len(test.expected) == 0 {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			assert.Equal(t, test.expected, v.String())
+		})
+	}
+}
+
+// Checks that parsing never panics and that parsing the String of a constraint yields the same constraint.
+func fuzzConstraintRoundTrip(f *testing.F, parse func(string) (VersionConstraint, error), seeds ...string) {
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		c, err := parse(s)
+		if err != nil {
+			return
+		}
+		rc, err := parse(c.String())
+		require.NoError(t, err, "parsing %q from %q", c.String(), s)
+		assert.Equal(t, c.String(), rc.String())
+
+		versions, rVersions := c.Versions(), rc.Versions()
+		require.Len(t, rVersions, len(versions))
+		for i := range versions {
+			assert.True(t, versions[i].Equal(rVersions[i]), "%s == %s", versions[i], rVersions[i])
+		}
+	})
+}
+
+func FuzzParseConstraint(f *testing.F) {
+	fuzzConstraintRoundTrip(f, func(s string) (VersionConstraint, error) {
+		c, err := ParseConstraint(s, ParseSemanticVersion)
+		if err != nil {
+			return nil, err
+		}
+		return *c, nil
+	}, "=v1.2.3", "!=1.2.3", ">1.2.3-rc.1", "<1", ">=1.2", "<=1.2.3+build", "=>1.2.3", "()v1.2.3")
+}
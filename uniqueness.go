@@ -0,0 +1,42 @@
+package main
+
+import "strconv"
+
+// Groups versions of a project that cannot be installed side by side.
+// Versions with different keys may coexist in a solution,
+// like npm nested dependencies or semver incompatible Rust crates.
+type UniquenessKey func(v Version) string
+
+// MajorVersionKey allows one version per major version.
+// Go modules v0 and v1 and +incompatible versions share a key,
+// because they use the same module path.
+// Versions of other schemes share a single key.
+func MajorVersionKey(v Version) string {
+	switch v := v.(type) {
+	case *SemanticVersion:
+		return strconv.FormatUint(v.Major(), 10)
+	case *GoModuleVersion:
+		if v.Major() == "v0" || v.IsIncompatible() {
+			return "v1"
+		}
+		return v.Major()
+	}
+	return ""
+}
+
+// SemverCompatibleKey allows one version per range of semver compatible versions,
+// as Cargo does: 1.2.0 and 1.5.0 share a key, as do 0.2.0 and 0.2.7, but 0.0.1 and 0.0.2 don't.
+// Versions of other schemes share a single key.
+func SemverCompatibleKey(v Version) string {
+	sv, ok := v.(*SemanticVersion)
+	if !ok {
+		return ""
+	}
+	switch {
+	case sv.Major() != 0:
+		return strconv.FormatUint(sv.Major(), 10)
+	case sv.Minor() != 0:
+		return "0." + strconv.FormatUint(sv.Minor(), 10)
+	}
+	return "0.0." + strconv.FormatUint(sv.Patch(), 10)
+}
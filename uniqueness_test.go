@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMajorVersionKey(t *testing.T) {
+	tests := []struct {
+		version Version
+		key     string
+	}{
+		{MustSemanticVersion("1.2.3"), "1"},
+		{MustSemanticVersion("0.2.3"), "0"},
+		{MustSemanticVersion("2.0.0-rc.1"), "2"},
+		{MustGoModuleVersion("v0.1.0"), "v1"},
+		{MustGoModuleVersion("v1.5.0"), "v1"},
+		{MustGoModuleVersion("v3.0.0+incompatible"), "v1"},
+		{MustGoModuleVersion("v2.1.0"), "v2"},
+		{MustSequenceVersion("7"), ""},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.key, MajorVersionKey(test.version), test.version.String())
+	}
+}
+
+func TestSemverCompatibleKey(t *testing.T) {
+	tests := []struct {
+		version Version
+		key     string
+	}{
+		{MustSemanticVersion("1.2.3"), "1"},
+		{MustSemanticVersion("1.5.0"), "1"},
+		{MustSemanticVersion("0.2.0"), "0.2"},
+		{MustSemanticVersion("0.2.7"), "0.2"},
+		{MustSemanticVersion("0.0.1"), "0.0.1"},
+		{MustSemanticVersion("0.0.2"), "0.0.2"},
+		{MustSequenceVersion("7"), ""},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.key, SemverCompatibleKey(test.version), test.version.String())
+	}
+}
@@ -10,15 +10,47 @@ type Version interface {
 	Equal(v Version) bool
 	Less(v Version) bool
 	String() string
+	// Scheme returns the name of the VersionScheme this version belongs to.
+	Scheme() string
 }
 
+// Implemented by versions that can be pre-releases.
+type PrereleaseVersion interface {
+	Version
+	IsPrerelease() bool
+}
+
+func isPrerelease(v Version) bool {
+	pv, ok := v.(PrereleaseVersion)
+	return ok && pv.IsPrerelease()
+}
+
+// Controls how build metadata takes part in comparing SemanticVersions.
+type BuildMetadataMode int
+
+const (
+	// Build metadata is ignored, as specified by semver 2.0.0.
+	// Versions only differing in metadata are equal
+	// and only the first of them is kept by the InMemoryDB.
+	BuildMetadataIgnore BuildMetadataMode = iota
+	// Build metadata breaks ties between otherwise equal versions.
+	// Versions without metadata sort first,
+	// metadata identifiers are compared like pre-release identifiers.
+	BuildMetadataTieBreak
+	// Build metadata is ignored,
+	// but versions only differing in metadata are rejected by the InMemoryDB.
+	BuildMetadataReject
+)
+
 // Represents a Semantic Version v2.
 type SemanticVersion struct {
 	*semver.Version
+	metadataMode BuildMetadataMode
 }
 
 var (
-	_ Version = (*SemanticVersion)(nil)
+	_ Version           = (*SemanticVersion)(nil)
+	_ PrereleaseVersion = (*SemanticVersion)(nil)
 )
 
 func MustSemanticVersion(v string) *SemanticVersion {
@@ -38,24 +70,77 @@ func NewSemanticVersion(v string) (*SemanticVersion, error) {
 	return &SemanticVersion{Version: sv}, nil
 }
 
+// NewSemanticVersionWithBuildMetadata parses a SemanticVersion using the given BuildMetadataMode.
+func NewSemanticVersionWithBuildMetadata(v string, mode BuildMetadataMode) (*SemanticVersion, error) {
+	sv, err := NewSemanticVersion(v)
+	if err != nil {
+		return nil, err
+	}
+	sv.metadataMode = mode
+	return sv, nil
+}
+
 func ParseSemanticVersion(v string) (Version, error) {
 	return NewSemanticVersion(v)
 }
 
+// SemanticVersionParser returns a VersionParser for SemanticVersions using the given BuildMetadataMode.
+func SemanticVersionParser(mode BuildMetadataMode) VersionParser {
+	return func(v string) (Version, error) {
+		return NewSemanticVersionWithBuildMetadata(v, mode)
+	}
+}
+
+func (sv *SemanticVersion) BuildMetadataMode() BuildMetadataMode {
+	return sv.metadataMode
+}
+
 func (sv *SemanticVersion) Equal(v Version) bool {
 	otherSV, ok := v.(*SemanticVersion)
-	if !ok {
+	if !ok || otherSV == nil {
 		return false
 	}
-	return sv.Version.Equal(otherSV.Version)
+	return sv.compare(otherSV) == 0
 }
 
 func (sv *SemanticVersion) Less(v Version) bool {
 	otherSV, ok := v.(*SemanticVersion)
-	if !ok {
+	if !ok || otherSV == nil {
 		return false
 	}
-	return sv.Version.LessThan(otherSV.Version)
+	return sv.compare(otherSV) < 0
+}
+
+// Build metadata is compared, if either version uses BuildMetadataTieBreak.
+func (sv *SemanticVersion) compare(other *SemanticVersion) int {
+	if c := sv.Version.Compare(other.Version); c != 0 {
+		return c
+	}
+	if sv.metadataMode != BuildMetadataTieBreak &&
+		other.metadataMode != BuildMetadataTieBreak {
+		return 0
+	}
+	return compareBuildMetadata(sv.Metadata(), other.Metadata())
+}
+
+func compareBuildMetadata(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case len(a) == 0:
+		return -1
+	case len(b) == 0:
+		return 1
+	}
+	return compareSemverPrerelease("-"+a, "-"+b)
+}
+
+func (sv *SemanticVersion) Scheme() string {
+	return SemanticVersionScheme.Name
+}
+
+func (sv *SemanticVersion) IsPrerelease() bool {
+	return len(sv.Prerelease()) != 0
 }
 
 // Sequence version is just an increasing number.
@@ -99,9 +184,13 @@ func (sv SequenceVersion) Less(v Version) bool {
 	if !ok {
 		return false
 	}
-	return int(otherSV) < int(sv)
+	return int(sv) < int(otherSV)
 }
 
 func (sv SequenceVersion) String() string {
 	return strconv.Itoa(int(sv))
 }
+
+func (sv SequenceVersion) Scheme() string {
+	return SequenceVersionScheme.Name
+}
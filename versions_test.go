@@ -1,7 +1,12 @@
 package main
 
 import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
 	"testing"
+	"testing/quick"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -36,8 +41,8 @@ func TestSequenceVersion(t *testing.T) {
 	assert.False(t, sv.Equal(MustSequenceVersion("120")))
 
 	assert.False(t, sv.Less(nil))
-	assert.False(t, sv.Less(MustSequenceVersion("145")))
-	assert.True(t, sv.Less(MustSequenceVersion("14")))
+	assert.True(t, sv.Less(MustSequenceVersion("145")))
+	assert.False(t, sv.Less(MustSequenceVersion("14")))
 
 	_, err = NewSequenceVersion("xxx")
 	require.Error(t, err)
@@ -48,3 +53,198 @@ func TestSequenceVersion(t *testing.T) {
 
 	assert.Equal(t, "123", sv.String())
 }
+
+func TestSemanticVersion_buildMetadata(t *testing.T) {
+	tests := []struct {
+		name  string
+		mode  BuildMetadataMode
+		a, b  string
+		equal bool
+		less  bool
+	}{
+		{name: "ignore", mode: BuildMetadataIgnore, a: "1.0.0+a", b: "1.0.0+b", equal: true},
+		{name: "reject", mode: BuildMetadataReject, a: "1.0.0+a", b: "1.0.0+b", equal: true},
+		{name: "tie-break", mode: BuildMetadataTieBreak, a: "1.0.0+a", b: "1.0.0+b", less: true},
+		{name: "tie-break without metadata", mode: BuildMetadataTieBreak, a: "1.0.0", b: "1.0.0+a", less: true},
+		{name: "tie-break numeric", mode: BuildMetadataTieBreak, a: "1.0.0+build.2", b: "1.0.0+build.10", less: true},
+		{name: "tie-break same", mode: BuildMetadataTieBreak, a: "1.0.0+a", b: "1.0.0+a", equal: true},
+		{name: "tie-break precedence first", mode: BuildMetadataTieBreak, a: "1.0.0+z", b: "1.0.1+a", less: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a, err := NewSemanticVersionWithBuildMetadata(test.a, test.mode)
+			require.NoError(t, err)
+			b, err := NewSemanticVersionWithBuildMetadata(test.b, test.mode)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.equal, a.Equal(b))
+			assert.Equal(t, test.equal, b.Equal(a))
+			assert.Equal(t, test.less, a.Less(b))
+			assert.False(t, b.Less(a))
+		})
+	}
+}
+
+// Generates random versions of one scheme from a small pool of components,
+// so that ties and near-ties are common.
+type versionGenerator func(r *rand.Rand) Version
+
+func pick(r *rand.Rand, options ...string) string {
+	return options[r.Intn(len(options))]
+}
+
+var versionGenerators = map[string]versionGenerator{
+	"semver": func(r *rand.Rand) Version {
+		return MustSemanticVersion(fmt.Sprintf("%d.%d.%d%s%s",
+			r.Intn(3), r.Intn(3), r.Intn(3),
+			pick(r, "", "", "-alpha", "-alpha.1", "-beta", "-1", "-rc.2"),
+			pick(r, "", "+build")))
+	},
+	"sequence": func(r *rand.Rand) Version {
+		return SequenceVersion(r.Intn(5))
+	},
+	"calver": func(r *rand.Rand) Version {
+		return MustCalendarVersion(DefaultCalendarVersionFormat, fmt.Sprintf("%d.0%d.0%d",
+			2020+r.Intn(2), 1+r.Intn(2), 1+r.Intn(3)))
+	},
+	"pep440": func(r *rand.Rand) Version {
+		return MustPEP440Version(
+			pick(r, "", "", "1!") +
+				pick(r, "1", "1.0", "1.0.0", "1.1", "2") +
+				pick(r, "", "", "a1", "b2", "rc1") +
+				pick(r, "", "", ".post1") +
+				pick(r, "", "", ".dev0") +
+				pick(r, "", "", "+local.1"))
+	},
+	"debian": func(r *rand.Rand) Version {
+		return MustDebianVersion(
+			pick(r, "", "", "1:") +
+				pick(r, "1.0", "1.0~rc1", "1.0~", "1.0+b1", "1.0a", "1.01", "2") +
+				pick(r, "", "-1", "-1ubuntu1", "-2"))
+	},
+	"rpm": func(r *rand.Rand) Version {
+		return MustRPMVersion(
+			pick(r, "", "", "1:") +
+				pick(r, "1.0", "1.0~rc1", "1.0^git1", "1.0a", "1.01", "2") +
+				pick(r, "", "-1", "-1.el9", "-2"))
+	},
+	"maven": func(r *rand.Rand) Version {
+		return MustMavenVersion(
+			pick(r, "1", "1.0", "1.0.0", "1.1", "2") +
+				pick(r, "", "", "-SNAPSHOT", "-alpha-1", "-beta2", "-rc1", "-sp1", "-1", "-abc"))
+	},
+	"gomod": func(r *rand.Rand) Version {
+		return MustGoModuleVersion(pick(r,
+			"v0.0.0-20230101120000-abcdef123456", "v0.1.0", "v1.0.0", "v1.0.0-rc.1",
+			"v1.0.1-0.20230101120000-abcdef123456", "v1.2.0", "v2.0.0+incompatible", "v2.1.0"))
+	},
+}
+
+// A known ascending pair per scheme, so a consistently inverted order is caught.
+var versionOrderAnchors = map[string][2]Version{
+	"semver":   {MustSemanticVersion("1.0.0"), MustSemanticVersion("1.0.1")},
+	"sequence": {SequenceVersion(1), SequenceVersion(2)},
+	"calver": {
+		MustCalendarVersion(DefaultCalendarVersionFormat, "2020.01.01"),
+		MustCalendarVersion(DefaultCalendarVersionFormat, "2020.01.02"),
+	},
+	"pep440": {MustPEP440Version("1.0"), MustPEP440Version("1.1")},
+	"debian": {MustDebianVersion("1.0"), MustDebianVersion("1.1")},
+	"rpm":    {MustRPMVersion("1.0"), MustRPMVersion("1.1")},
+	"maven":  {MustMavenVersion("1.0"), MustMavenVersion("1.1")},
+	"gomod":  {MustGoModuleVersion("v1.0.0"), MustGoModuleVersion("v1.1.0")},
+}
+
+// Checks trichotomy, transitivity and operator duality for every Version implementation.
+func TestVersion_properties(t *testing.T) {
+	var schemes []string
+	for name := range DefaultVersionSchemes.schemes {
+		schemes = append(schemes, name)
+	}
+	sort.Strings(schemes)
+
+	for _, scheme := range schemes {
+		gen, ok := versionGenerators[scheme]
+		require.True(t, ok, "no generator for scheme %q", scheme)
+
+		t.Run(scheme, func(t *testing.T) {
+			anchors, ok := versionOrderAnchors[scheme]
+			require.True(t, ok, "no order anchors for scheme %q", scheme)
+			assert.True(t, anchors[0].Less(anchors[1]), "%s < %s", anchors[0], anchors[1])
+			assert.True(t, NewConstraint(Greater, anchors[0]).Matches(anchors[1]),
+				"%s > %s", anchors[1], anchors[0])
+
+			config := &quick.Config{
+				MaxCount: 2000,
+				Values: func(args []reflect.Value, r *rand.Rand) {
+					for i := range args {
+						args[i] = reflect.ValueOf(gen(r))
+					}
+				},
+			}
+
+			trichotomy := func(a, b Version) bool {
+				var n int
+				for _, holds := range []bool{a.Less(b), a.Equal(b), b.Less(a)} {
+					if holds {
+						n++
+					}
+				}
+				return n == 1 && a.Equal(b) == b.Equal(a)
+			}
+			assert.NoError(t, quick.Check(trichotomy, config), "trichotomy")
+
+			transitivity := func(a, b, c Version) bool {
+				if a.Less(b) && b.Less(c) && !a.Less(c) {
+					return false
+				}
+				if a.Equal(b) && b.Equal(c) && !a.Equal(c) {
+					return false
+				}
+				return true
+			}
+			assert.NoError(t, quick.Check(transitivity, config), "transitivity")
+
+			duality := func(a, b Version) bool {
+				m := func(op Operator, v, c Version) bool {
+					return NewConstraint(op, c).Matches(v)
+				}
+				return m(Equal, a, b) == a.Equal(b) &&
+					m(Less, a, b) == a.Less(b) &&
+					m(Greater, a, b) == b.Less(a) &&
+					m(NotEqual, a, b) == !m(Equal, a, b) &&
+					m(GreaterOrEqual, a, b) == !m(Less, a, b) &&
+					m(LessOrEqual, a, b) == !m(Greater, a, b) &&
+					m(Less, a, b) == m(Greater, b, a) &&
+					m(LessOrEqual, a, b) == m(GreaterOrEqual, b, a)
+			}
+			assert.NoError(t, quick.Check(duality, config), "duality")
+		})
+	}
+}
+
+// Checks that parsing never panics and that parsing the String of a version yields an equal version.
+func fuzzVersionRoundTrip(f *testing.F, parse VersionParser, seeds ...string) {
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		v, err := parse(s)
+		if err != nil {
+			return
+		}
+		rv, err := parse(v.String())
+		require.NoError(t, err, "parsing %q from %q", v.String(), s)
+		assert.True(t, v.Equal(rv), "%s == %s", v, rv)
+		assert.Equal(t, v.String(), rv.String())
+	})
+}
+
+func FuzzNewSemanticVersion(f *testing.F) {
+	fuzzVersionRoundTrip(f, ParseSemanticVersion,
+		"v1.0.0", "1.2.3-alpha.1+build.5", "1", "1.2", "01.2.3", "1.2.3-0a.1", "xxx")
+}
+
+func FuzzNewSequenceVersion(f *testing.F) {
+	fuzzVersionRoundTrip(f, ParseSequenceVersion, "123", "0", "-1", "+5", "xxx")
+}